@@ -0,0 +1,238 @@
+// Package fake provides an in-memory implementation of the Uptrace monitor
+// API, so that the provider and modules using it can be exercised without a
+// real Uptrace installation.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	uptrace "github.com/persona-ae/terraform-provider-uptrace/internal/services"
+)
+
+var _ uptrace.MonitorAPI = &Client{}
+
+// Client is an in-memory Uptrace backend. It assigns ids, fills in the
+// defaults Uptrace applies, validates monitors and maintains timestamps. It
+// is safe for concurrent use.
+type Client struct {
+	// ReadOnly makes writes fail with uptrace.ErrReadOnly, like the real
+	// client.
+	ReadOnly bool
+
+	// Now returns the current time used for timestamps.
+	Now func() time.Time
+
+	// MaxPageSize caps the number of monitors listed per page when positive,
+	// like servers enforcing a smaller page size than requested.
+	MaxPageSize int
+	// IgnorePaging makes listings return every monitor without a count,
+	// like servers not supporting pagination.
+	IgnorePaging bool
+
+	mu        sync.Mutex
+	projectID string
+	nextID    int32
+	monitors  map[string]map[int32]uptrace.Monitor
+}
+
+// NewClient returns an empty backend with defaultProjectID as the default
+// project.
+func NewClient(defaultProjectID string) *Client {
+	return &Client{
+		Now:       time.Now,
+		projectID: defaultProjectID,
+		nextID:    1,
+		monitors:  map[string]map[int32]uptrace.Monitor{},
+	}
+}
+
+func (c *Client) DefaultProjectID() string {
+	return c.projectID
+}
+
+// Monitors returns the monitors stored in a project, ordered by id.
+func (c *Client) Monitors(projectID string) []uptrace.Monitor {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	monitors := make([]uptrace.Monitor, 0, len(c.monitors[projectID]))
+	for _, m := range c.monitors[projectID] {
+		monitors = append(monitors, m)
+	}
+	sort.Slice(monitors, func(i, j int) bool { return monitors[i].ID < monitors[j].ID })
+	return monitors
+}
+
+// GetMonitors filters the monitors like Uptrace: Query matches names case
+// insensitively, Type and State match exactly. Count is the number of
+// matching monitors, regardless of the page. See MaxPageSize and
+// IgnorePaging to mimic other paging behaviours.
+func (c *Client) GetMonitors(ctx context.Context, projectID string, opts uptrace.ListMonitorsOptions, out *uptrace.GetMonitorsResponse) error {
+	if _, err := parseProjectID(projectID); err != nil {
+		return err
+	}
+
+	monitors := make([]uptrace.Monitor, 0)
+	for _, m := range c.Monitors(projectID) {
+		if opts.Query != "" && !strings.Contains(strings.ToLower(m.Name), strings.ToLower(opts.Query)) {
+			continue
+		}
+		if (opts.Type != "" && m.Type != opts.Type) || (opts.State != "" && m.Status != opts.State) {
+			continue
+		}
+		monitors = append(monitors, m)
+	}
+	if c.IgnorePaging {
+		*out = uptrace.GetMonitorsResponse{Monitors: monitors}
+		return nil
+	}
+	count := len(monitors)
+
+	limit := opts.Limit
+	if c.MaxPageSize > 0 && (limit <= 0 || limit > c.MaxPageSize) {
+		limit = c.MaxPageSize
+	}
+	monitors = monitors[min(opts.Offset, count):]
+	if limit > 0 && limit < len(monitors) {
+		monitors = monitors[:limit]
+	}
+	*out = uptrace.GetMonitorsResponse{Count: count, Monitors: monitors}
+	return nil
+}
+
+func (c *Client) GetMonitorById(ctx context.Context, projectID, id string, out *uptrace.MonitorResponse) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m, err := c.lookup(projectID, id)
+	if err != nil {
+		return err
+	}
+	out.Monitor = m
+	return nil
+}
+
+func (c *Client) CreateMonitor(ctx context.Context, projectID string, req uptrace.Monitor, out *uptrace.MonitorResponse) error {
+	if c.ReadOnly {
+		return uptrace.ErrReadOnly
+	}
+	project, err := parseProjectID(projectID)
+	if err != nil {
+		return err
+	}
+
+	m := req
+	applyDefaults(&m)
+	if err := validate(m); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := timestamp(c.Now())
+	m.ID = c.nextID
+	m.ProjectID = project
+	m.CreatedAt = now
+	m.UpdatedAt = now
+	c.nextID++
+
+	if c.monitors[projectID] == nil {
+		c.monitors[projectID] = map[int32]uptrace.Monitor{}
+	}
+	c.monitors[projectID][m.ID] = m
+
+	out.Monitor = m
+	return nil
+}
+
+func (c *Client) UpdateMonitor(ctx context.Context, projectID, id string, req uptrace.Monitor, out *uptrace.MonitorResponse) error {
+	if c.ReadOnly {
+		return uptrace.ErrReadOnly
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	existing, err := c.lookup(projectID, id)
+	if err != nil {
+		return err
+	}
+
+	m := req
+	applyDefaults(&m)
+	if err := validate(m); err != nil {
+		return err
+	}
+
+	m.ID = existing.ID
+	m.ProjectID = existing.ProjectID
+	m.CreatedAt = existing.CreatedAt
+	m.CheckedAt = existing.CheckedAt
+	m.UpdatedAt = timestamp(c.Now())
+	c.monitors[projectID][m.ID] = m
+
+	out.Monitor = m
+	return nil
+}
+
+func (c *Client) DeleteMonitor(ctx context.Context, projectID, id string) error {
+	if c.ReadOnly {
+		return uptrace.ErrReadOnly
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m, err := c.lookup(projectID, id)
+	if err != nil {
+		return err
+	}
+	delete(c.monitors[projectID], m.ID)
+	return nil
+}
+
+// lookup returns a stored monitor. The caller must hold c.mu.
+func (c *Client) lookup(projectID, id string) (uptrace.Monitor, error) {
+	if _, err := parseProjectID(projectID); err != nil {
+		return uptrace.Monitor{}, err
+	}
+	monitorID, err := strconv.ParseInt(id, 10, 32)
+	if err != nil {
+		return uptrace.Monitor{}, notFound(fmt.Sprintf("monitor %q not found", id))
+	}
+	m, ok := c.monitors[projectID][int32(monitorID)]
+	if !ok {
+		return uptrace.Monitor{}, notFound(fmt.Sprintf("monitor %q not found", id))
+	}
+	return m, nil
+}
+
+func parseProjectID(projectID string) (int32, error) {
+	id, err := strconv.ParseInt(projectID, 10, 32)
+	if err != nil || id <= 0 {
+		return 0, notFound(fmt.Sprintf("project %q not found", projectID))
+	}
+	return int32(id), nil
+}
+
+// timestamp converts t to the unix milliseconds used by Uptrace.
+func timestamp(t time.Time) float64 {
+	return float64(t.UnixMilli())
+}
+
+func notFound(message string) *uptrace.APIError {
+	return &uptrace.APIError{
+		StatusCode: http.StatusNotFound,
+		Status:     "404 Not Found",
+		Code:       "not_found",
+		Message:    message,
+	}
+}
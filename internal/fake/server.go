@@ -0,0 +1,185 @@
+package fake
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+
+	uptrace "github.com/persona-ae/terraform-provider-uptrace/internal/services"
+)
+
+// Server is a local stand-in for the Uptrace monitor API, backed by a Client.
+// It mimics the response shapes and error bodies of Uptrace, so the real HTTP
+// client can be tested offline.
+type Server struct {
+	*httptest.Server
+
+	// Backend holds the monitors served.
+	Backend *Client
+
+	// APIKey is the token requests must be authenticated with.
+	APIKey string
+}
+
+// NewServer starts a server for the API key apiKey, with defaultProjectID as
+// the backend's default project. Call Close when done.
+func NewServer(apiKey, defaultProjectID string) *Server {
+	s := &Server{
+		Backend: NewClient(defaultProjectID),
+		APIKey:  apiKey,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /internal/v1/projects/{project}/monitors", s.listMonitors)
+	mux.HandleFunc("POST /internal/v1/projects/{project}/monitors", s.createMonitor)
+	mux.HandleFunc("GET /internal/v1/projects/{project}/monitors/{id}", s.getMonitor)
+	mux.HandleFunc("PUT /internal/v1/projects/{project}/monitors/{id}", s.updateMonitor)
+	mux.HandleFunc("DELETE /internal/v1/projects/{project}/monitors/{id}", s.deleteMonitor)
+
+	s.Server = httptest.NewServer(s.authenticate(mux))
+	return s
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token != s.APIKey {
+			writeError(w, &uptrace.APIError{
+				StatusCode: http.StatusUnauthorized,
+				Code:       "unauthorized",
+				Message:    "invalid or missing API token",
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) listMonitors(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	opts := uptrace.ListMonitorsOptions{
+		Query: query.Get("q"),
+		Type:  query.Get("type"),
+		State: query.Get("state"),
+	}
+	var ok bool
+	if opts.Limit, ok = readInt(w, query, "limit"); !ok {
+		return
+	}
+	if opts.Offset, ok = readInt(w, query, "offset"); !ok {
+		return
+	}
+
+	var out uptrace.GetMonitorsResponse
+	if err := s.Backend.GetMonitors(r.Context(), r.PathValue("project"), opts, &out); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) getMonitor(w http.ResponseWriter, r *http.Request) {
+	var out uptrace.MonitorResponse
+	if err := s.Backend.GetMonitorById(r.Context(), r.PathValue("project"), r.PathValue("id"), &out); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) createMonitor(w http.ResponseWriter, r *http.Request) {
+	var in uptrace.Monitor
+	if !readJSON(w, r, &in) {
+		return
+	}
+	var out uptrace.MonitorResponse
+	if err := s.Backend.CreateMonitor(r.Context(), r.PathValue("project"), in, &out); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) updateMonitor(w http.ResponseWriter, r *http.Request) {
+	var in uptrace.Monitor
+	if !readJSON(w, r, &in) {
+		return
+	}
+	var out uptrace.MonitorResponse
+	if err := s.Backend.UpdateMonitor(r.Context(), r.PathValue("project"), r.PathValue("id"), in, &out); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) deleteMonitor(w http.ResponseWriter, r *http.Request) {
+	if err := s.Backend.DeleteMonitor(r.Context(), r.PathValue("project"), r.PathValue("id")); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+// readInt parses the non-negative integer query parameter name, zero when it
+// is missing.
+func readInt(w http.ResponseWriter, query url.Values, name string) (int, bool) {
+	if !query.Has(name) {
+		return 0, true
+	}
+	n, err := strconv.Atoi(query.Get(name))
+	if err != nil || n < 0 {
+		writeError(w, &uptrace.APIError{
+			StatusCode: http.StatusBadRequest,
+			Code:       "invalid_query",
+			Message:    fmt.Sprintf("%s must be a non-negative integer, got %q", name, query.Get(name)),
+		})
+		return 0, false
+	}
+	return n, true
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, &uptrace.APIError{
+			StatusCode: http.StatusBadRequest,
+			Code:       "invalid_json",
+			Message:    err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err in the shape of an Uptrace error body.
+func writeError(w http.ResponseWriter, err error) {
+	var apiErr *uptrace.APIError
+	if !errors.As(err, &apiErr) {
+		apiErr = &uptrace.APIError{
+			StatusCode: http.StatusInternalServerError,
+			Code:       "internal",
+			Message:    err.Error(),
+		}
+	}
+
+	writeJSON(w, apiErr.StatusCode, struct {
+		Code    string               `json:"code"`
+		Message string               `json:"message"`
+		Errors  []uptrace.FieldError `json:"errors,omitempty"`
+	}{
+		Code:    apiErr.Code,
+		Message: apiErr.Message,
+		Errors:  apiErr.Fields,
+	})
+}
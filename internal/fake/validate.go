@@ -0,0 +1,100 @@
+package fake
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	uptrace "github.com/persona-ae/terraform-provider-uptrace/internal/services"
+)
+
+// applyDefaults fills the fields Uptrace defaults when they are left empty.
+func applyDefaults(m *uptrace.Monitor) {
+	defaults := uptrace.MakeMonitorWithDefaults()
+
+	if m.Status == "" {
+		m.Status = defaults.Status
+	}
+	if m.RepeatInterval.Strategy == "" {
+		m.RepeatInterval = defaults.RepeatInterval
+	}
+	if m.TeamIDs == nil {
+		m.TeamIDs = []int32{}
+	}
+	if m.ChannelIDs == nil {
+		m.ChannelIDs = []int32{}
+	}
+	if m.Params.Metrics == nil {
+		m.Params.Metrics = []uptrace.Metric{}
+	}
+
+	p := &m.Params
+	if p.ColumnUnit == "" {
+		p.ColumnUnit = defaults.Params.ColumnUnit
+	}
+	if p.BoundsSource == "" {
+		p.BoundsSource = defaults.Params.BoundsSource
+	}
+	if p.GroupingInterval == 0 {
+		p.GroupingInterval = defaults.Params.GroupingInterval
+	}
+	if p.CheckNumPoint == 0 {
+		p.CheckNumPoint = defaults.Params.CheckNumPoint
+	}
+	if p.NullsMode == "" {
+		p.NullsMode = defaults.Params.NullsMode
+	}
+	if p.Tolerance == "" {
+		p.Tolerance = defaults.Params.Tolerance
+	}
+	if p.TrainingPeriod == 0 {
+		p.TrainingPeriod = defaults.Params.TrainingPeriod
+	}
+}
+
+// validate applies the checks Uptrace performs on a monitor, reporting
+// them in the same shape as the API.
+func validate(m uptrace.Monitor) error {
+	var fields []uptrace.FieldError
+	add := func(field, format string, args ...any) {
+		fields = append(fields, uptrace.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if m.Name == "" {
+		add("name", "name is required")
+	}
+	if !slices.Contains(uptrace.MonitorTypes, m.Type) {
+		add("type", "unsupported monitor type %q", m.Type)
+	}
+
+	if m.Type == "metric" {
+		if m.Params.Query == "" {
+			add("params.query", "query is required")
+		}
+		if len(m.Params.Metrics) == 0 {
+			add("params.metrics", "at least one metric is required")
+		}
+		for i, metric := range m.Params.Metrics {
+			if metric.Name == "" {
+				add(fmt.Sprintf("params.metrics[%d].name", i), "metric name is required")
+			}
+			if metric.Alias == "" {
+				add(fmt.Sprintf("params.metrics[%d].alias", i), "metric alias is required")
+			}
+		}
+		if m.Params.BoundsSource == "manual" && m.Params.MinAllowedValue == nil && m.Params.MaxAllowedValue == nil {
+			add("params.minAllowedValue", "at least minAllowedValue or maxAllowedValue is required")
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	return &uptrace.APIError{
+		StatusCode: http.StatusBadRequest,
+		Status:     "400 Bad Request",
+		Code:       "invalid_monitor",
+		Message:    "monitor is invalid",
+		Fields:     fields,
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	uptrace "github.com/persona-ae/terraform-provider-uptrace/internal/services"
+)
+
+// ProviderData is handed by the provider to its resources.
+type ProviderData struct {
+	Client uptrace.MonitorAPI
+
+	// MonitorDefaults holds the provider's monitor_defaults block, nil when
+	// the block is not configured.
+	MonitorDefaults *TFMonitorDefaults
+}
+
+// TFMonitorDefaults maps the monitor_defaults block of the provider. Every
+// attribute falls back to the Uptrace default when null.
+type TFMonitorDefaults struct {
+	NotifyEveryoneByEmail types.Bool    `tfsdk:"notify_everyone_by_email"`
+	RepeatInterval        types.String  `tfsdk:"repeat_interval"`
+	NullsMode             types.String  `tfsdk:"nulls_mode"`
+	Tolerance             types.String  `tfsdk:"tolerance"`
+	GroupingInterval      types.Int32   `tfsdk:"grouping_interval"`
+	CheckNumPoint         types.Int32   `tfsdk:"check_num_point"`
+	TimeOffset            types.Int32   `tfsdk:"time_offset"`
+	TrainingPeriod        types.Int32   `tfsdk:"training_period"`
+	MinDevFraction        types.Float64 `tfsdk:"min_dev_fraction"`
+	TeamIDs               types.List    `tfsdk:"team_ids"`
+	ChannelIDs            types.List    `tfsdk:"channel_ids"`
+}
@@ -0,0 +1,273 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/persona-ae/terraform-provider-uptrace/internal/models"
+	uptrace "github.com/persona-ae/terraform-provider-uptrace/internal/services"
+)
+
+// uptraceProviderModel maps the provider schema data.
+type uptraceProviderModel struct {
+	APIKey    types.String `tfsdk:"api_key"`
+	ProjectID types.String `tfsdk:"project_id"`
+	Endpoint  types.String `tfsdk:"endpoint"`
+	DSN       types.String `tfsdk:"dsn"`
+	Profile   types.String `tfsdk:"profile"`
+
+	MaxRetries   types.Int64  `tfsdk:"max_retries"`
+	RetryWaitMin types.String `tfsdk:"retry_wait_min"`
+	RetryWaitMax types.String `tfsdk:"retry_wait_max"`
+
+	RequestsPerSecond     types.Float64 `tfsdk:"requests_per_second"`
+	MaxConcurrentRequests types.Int64   `tfsdk:"max_concurrent_requests"`
+
+	CacheTTL types.String `tfsdk:"cache_ttl"`
+
+	RequestTimeout     types.String `tfsdk:"request_timeout"`
+	CACertPEM          types.String `tfsdk:"ca_cert_pem"`
+	CACertFile         types.String `tfsdk:"ca_cert_file"`
+	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
+	ProxyURL           types.String `tfsdk:"proxy_url"`
+	ClientCertPEM      types.String `tfsdk:"client_cert_pem"`
+	ClientCertFile     types.String `tfsdk:"client_cert_file"`
+	ClientKeyPEM       types.String `tfsdk:"client_key_pem"`
+	ClientKeyFile      types.String `tfsdk:"client_key_file"`
+
+	ExtraUserAgent types.String `tfsdk:"extra_user_agent"`
+
+	SkipCredentialsValidation types.Bool `tfsdk:"skip_credentials_validation"`
+	ReadOnly                  types.Bool `tfsdk:"read_only"`
+
+	MonitorDefaults *models.TFMonitorDefaults `tfsdk:"monitor_defaults"`
+}
+
+// valueOrEnv returns the configured value, falling back to the environment
+// variable envVar when the attribute is not set.
+func valueOrEnv(value types.String, envVar string) string {
+	if !value.IsNull() && !value.IsUnknown() {
+		return value.ValueString()
+	}
+	return os.Getenv(envVar)
+}
+
+// credentialSources resolves settings that may come from the configuration,
+// the environment or a credentials profile.
+type credentialSources struct {
+	profile map[string]string
+
+	// selected is set when the profile was chosen explicitly, rather than
+	// being the default profile.
+	selected bool
+}
+
+// lookup returns the first value set, in order of precedence: the attribute,
+// an explicitly selected profile, the environment variable envVar and the
+// default profile.
+func (c credentialSources) lookup(value types.String, envVar, key string) string {
+	if !value.IsNull() && !value.IsUnknown() {
+		return value.ValueString()
+	}
+	if c.selects(key) {
+		return c.profile[key]
+	}
+	if env := os.Getenv(envVar); env != "" {
+		return env
+	}
+	return c.profile[key]
+}
+
+// selects reports whether an explicitly selected profile sets key.
+func (c credentialSources) selects(key string) bool {
+	return c.selected && c.profile[key] != ""
+}
+
+// loadCredentialSources loads the profile selected with the profile attribute
+// or UPTRACE_PROFILE, or the default profile if the credentials file has one.
+func loadCredentialSources(config uptraceProviderModel, diags *diag.Diagnostics) credentialSources {
+	var sources credentialSources
+
+	name := valueOrEnv(config.Profile, envProfile)
+	sources.selected = name != ""
+	if name == "" {
+		name = defaultProfile
+	}
+
+	filename, err := credentialsFilePath()
+	if err == nil {
+		sources.profile, err = loadProfile(filename, name)
+	}
+	switch {
+	case err == nil:
+	case !sources.selected && (errors.Is(err, fs.ErrNotExist) || errors.Is(err, errProfileNotFound)):
+		// Without an explicit selection the credentials file is optional.
+	case !sources.selected:
+		// Nor is a broken one fatal, as the credentials may come from
+		// elsewhere. They are reported missing otherwise.
+		diags.AddAttributeWarning(
+			path.Root("profile"),
+			"Ignored Uptrace provider credentials file",
+			fmt.Sprintf("The default credentials profile %q could not be loaded and is ignored: %s", name, err),
+		)
+	default:
+		diags.AddAttributeError(
+			path.Root("profile"),
+			"Invalid Uptrace provider profile",
+			fmt.Sprintf("The credentials profile %q could not be loaded: %s", name, err),
+		)
+	}
+	return sources
+}
+
+// checkUnknown reports value as an error when it is only known after apply.
+// envVar names the environment variable that may be used instead, if any.
+func checkUnknown(value attr.Value, attribute, envVar string, diags *diag.Diagnostics) {
+	if !value.IsUnknown() {
+		return
+	}
+	alternative := "or set the value statically in the configuration."
+	if envVar != "" {
+		alternative = fmt.Sprintf("set the value statically in the configuration, or use the %s environment variable.", envVar)
+	}
+	diags.AddAttributeError(
+		path.Root(attribute),
+		fmt.Sprintf("Unknown Uptrace provider %s", attribute),
+		fmt.Sprintf("The provider cannot create the Uptrace API client as there is an unknown configuration value for %q. "+
+			"Either target apply the source of the value first, %s", attribute, alternative),
+	)
+}
+
+// parseDuration parses a duration attribute, returning def when it is not
+// set.
+func parseDuration(value types.String, attribute string, def time.Duration, diags *diag.Diagnostics) time.Duration {
+	if value.IsNull() || value.IsUnknown() {
+		return def
+	}
+	d, err := time.ParseDuration(value.ValueString())
+	if err == nil && d < 0 {
+		err = fmt.Errorf("duration must not be negative")
+	}
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root(attribute),
+			fmt.Sprintf("Invalid Uptrace provider %s", attribute),
+			fmt.Sprintf("%q is not a valid duration such as \"500ms\" or \"30s\": %s", value.ValueString(), err),
+		)
+		return def
+	}
+	return d
+}
+
+func checkMissing(value, attribute, envVar string, diags *diag.Diagnostics) {
+	if value != "" {
+		return
+	}
+	diags.AddAttributeError(
+		path.Root(attribute),
+		fmt.Sprintf("Missing Uptrace provider %s", attribute),
+		fmt.Sprintf("The provider cannot create the Uptrace API client as there is a missing or empty value for %q. "+
+			"Set the %q attribute in the provider configuration, use the %s environment variable or "+
+			"select a credentials profile that sets it with the \"profile\" attribute or the %s environment variable. "+
+			"Alternatively configure a DSN with the \"dsn\" attribute or the %s environment variable.", attribute, attribute, envVar, envProfile, envDSN),
+	)
+}
+
+func checkConflict(value types.String, attribute, other string, diags *diag.Diagnostics) {
+	if value.IsNull() {
+		return
+	}
+	diags.AddAttributeError(
+		path.Root(attribute),
+		"Conflicting Uptrace provider configuration",
+		fmt.Sprintf("%q cannot be set together with %q, the DSN already provides it. Remove one of them.", attribute, other),
+	)
+}
+
+// transportOptions collects the HTTP transport settings, reading PEM files
+// from disk where the *_file variant of an attribute is used.
+func transportOptions(config uptraceProviderModel, diags *diag.Diagnostics) uptrace.TransportOptions {
+	return uptrace.TransportOptions{
+		Timeout:            parseDuration(config.RequestTimeout, "request_timeout", uptrace.DefaultRequestTimeout, diags),
+		CACertPEM:          pemOrFile(config.CACertPEM, "ca_cert_pem", config.CACertFile, "ca_cert_file", diags),
+		InsecureSkipVerify: config.InsecureSkipVerify.ValueBool(),
+		ProxyURL:           config.ProxyURL.ValueString(),
+		ClientCertPEM:      pemOrFile(config.ClientCertPEM, "client_cert_pem", config.ClientCertFile, "client_cert_file", diags),
+		ClientKeyPEM:       pemOrFile(config.ClientKeyPEM, "client_key_pem", config.ClientKeyFile, "client_key_file", diags),
+	}
+}
+
+// pemOrFile returns the inline PEM value or the contents of the file, at most
+// one of which may be set.
+func pemOrFile(pem types.String, pemAttribute string, file types.String, fileAttribute string, diags *diag.Diagnostics) string {
+	if !pem.IsNull() && !file.IsNull() {
+		diags.AddAttributeError(
+			path.Root(fileAttribute),
+			"Conflicting Uptrace provider configuration",
+			fmt.Sprintf("%q cannot be set together with %q. Remove one of them.", fileAttribute, pemAttribute),
+		)
+		return ""
+	}
+	if file.IsNull() {
+		return pem.ValueString()
+	}
+
+	contents, err := os.ReadFile(file.ValueString())
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root(fileAttribute),
+			fmt.Sprintf("Invalid Uptrace provider %s", fileAttribute),
+			fmt.Sprintf("Could not read %q: %s", file.ValueString(), err),
+		)
+		return ""
+	}
+	return string(contents)
+}
+
+// boolOrEnv returns the configured value, falling back to the environment
+// variable envVar when the attribute is not set.
+func boolOrEnv(value types.Bool, envVar string) bool {
+	if !value.IsNull() && !value.IsUnknown() {
+		return value.ValueBool()
+	}
+	b, _ := strconv.ParseBool(os.Getenv(envVar))
+	return b
+}
+
+// validateCredentials checks the API key and project with a request to
+// Uptrace at endpoint. keyAttribute and projectAttribute name the attributes
+// the credentials were configured with, eg. "dsn".
+func validateCredentials(ctx context.Context, client uptrace.MonitorAPI, endpoint, projectID, keyAttribute, projectAttribute string, diags *diag.Diagnostics) {
+	err := uptrace.CheckAccess(ctx, client, projectID)
+	switch {
+	case err == nil:
+	case uptrace.IsUnauthorized(err):
+		diags.AddAttributeError(
+			path.Root(keyAttribute),
+			"Invalid Uptrace API key",
+			fmt.Sprintf("Uptrace rejected the API key: %s", err),
+		)
+	case uptrace.IsForbidden(err), uptrace.IsNotFound(err):
+		diags.AddAttributeError(
+			path.Root(projectAttribute),
+			"Invalid Uptrace project",
+			fmt.Sprintf("The project %q does not exist or the API key has no access to it: %s", projectID, err),
+		)
+	default:
+		diags.AddError(
+			"Failed to validate Uptrace credentials",
+			fmt.Sprintf("The credentials could not be validated against %s: %s\n\n"+
+				"Set \"skip_credentials_validation\" or the %s environment variable to skip this check, "+
+				"eg. for offline plans.", endpoint, err, envSkipCredentialsValidation),
+		)
+	}
+}
@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/persona-ae/terraform-provider-uptrace/internal/models"
+	uptrace "github.com/persona-ae/terraform-provider-uptrace/internal/services"
+)
+
+// clearEnv unsets the environment variables the provider reads for the
+// duration of the test, and points UPTRACE_CONFIG_FILE at a missing file.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, envVar := range []string{
+		envAPIKey, envProjectID, envEndpoint, envDSN, envProfile,
+		envAppendUserAgent, envSkipCredentialsValidation, envReadOnly,
+	} {
+		t.Setenv(envVar, "")
+	}
+	t.Setenv(envConfigFile, filepath.Join(t.TempDir(), "missing"))
+}
+
+// configure configures the provider with the string attributes attrs and
+// returns the Uptrace API client it built. Credentials are not validated.
+func configure(t *testing.T, attrs map[string]string) (*uptrace.UptraceClient, diag.Diagnostics) {
+	t.Helper()
+	ctx := context.Background()
+	p := New("test")()
+
+	var schemaResp provider.SchemaResponse
+	p.Schema(ctx, provider.SchemaRequest{}, &schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+
+	values := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, typ := range objectType.AttributeTypes {
+		values[name] = tftypes.NewValue(typ, nil)
+	}
+	for name, value := range attrs {
+		values[name] = tftypes.NewValue(tftypes.String, value)
+	}
+	values["skip_credentials_validation"] = tftypes.NewValue(tftypes.Bool, true)
+
+	req := provider.ConfigureRequest{
+		Config: tfsdk.Config{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(objectType, values),
+		},
+	}
+	var resp provider.ConfigureResponse
+	p.Configure(ctx, req, &resp)
+	if resp.Diagnostics.HasError() {
+		return nil, resp.Diagnostics
+	}
+	data := resp.ResourceData.(*models.ProviderData)
+	return data.Client.(*uptrace.CachingClient).MonitorAPI.(*uptrace.UptraceClient), resp.Diagnostics
+}
+
+func TestCredentialSourcesLookup(t *testing.T) {
+	filename := writeCredentials(t, `
+[default]
+api_key = default-key
+
+[staging]
+api_key = staging-key
+
+[partial]
+project_id = 3
+`)
+
+	tests := []struct {
+		profile string
+		attr    string
+		env     string
+		want    string
+	}{
+		{want: "default-key"},
+		{env: "env-key", want: "env-key"},
+		{attr: "attr-key", env: "env-key", want: "attr-key"},
+		{profile: "staging", env: "env-key", want: "staging-key"},
+		{profile: "staging", attr: "attr-key", env: "env-key", want: "attr-key"},
+		// a selected profile without the key falls through to the
+		// environment, but not to the default profile
+		{profile: "partial", env: "env-key", want: "env-key"},
+		{profile: "partial", want: ""},
+	}
+	for _, test := range tests {
+		clearEnv(t)
+		t.Setenv(envConfigFile, filename)
+		t.Setenv(envAPIKey, test.env)
+
+		config := uptraceProviderModel{Profile: types.StringNull(), APIKey: types.StringNull()}
+		if test.profile != "" {
+			config.Profile = types.StringValue(test.profile)
+		}
+		if test.attr != "" {
+			config.APIKey = types.StringValue(test.attr)
+		}
+
+		var diags diag.Diagnostics
+		sources := loadCredentialSources(config, &diags)
+		if diags.HasError() {
+			t.Errorf("loadCredentialSources(%+v): %v", test, diags)
+			continue
+		}
+		if got := sources.lookup(config.APIKey, envAPIKey, "api_key"); got != test.want {
+			t.Errorf("lookup(%+v) = %q, want %q", test, got, test.want)
+		}
+	}
+}
+
+func TestLoadCredentialSourcesError(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(envConfigFile, writeCredentials(t, "[default]\ntoken = key\n"))
+
+	// A broken default profile is ignored, a selected one is an error.
+	var diags diag.Diagnostics
+	loadCredentialSources(uptraceProviderModel{}, &diags)
+	if diags.HasError() || diags.WarningsCount() != 1 {
+		t.Errorf("loadCredentialSources without a profile = %v, want a warning", diags)
+	}
+
+	diags = nil
+	loadCredentialSources(uptraceProviderModel{Profile: types.StringValue("default")}, &diags)
+	if !diags.HasError() {
+		t.Errorf("loadCredentialSources with the profile = %v, want an error", diags)
+	}
+}
+
+func TestConfigureDSNFromEnvWithProfile(t *testing.T) {
+	filename := writeCredentials(t, `
+[staging]
+api_key = staging-key
+
+[partial]
+project_id = 3
+`)
+
+	tests := []struct {
+		profile     string
+		wantKey     string
+		wantURL     string
+		wantProject string
+	}{
+		// the profile does not set the key, the DSN provides it
+		{"partial", "dsn-key", "https://uptrace.example.com", "3"},
+		// the key of the profile wins over the DSN
+		{"staging", "staging-key", uptrace.BaseURL, "1"},
+	}
+	for _, test := range tests {
+		clearEnv(t)
+		t.Setenv(envConfigFile, filename)
+		t.Setenv(envDSN, "https://dsn-key@uptrace.example.com")
+		t.Setenv(envProjectID, "1")
+
+		client, diags := configure(t, map[string]string{"profile": test.profile})
+		if diags.HasError() {
+			t.Errorf("profile %q: %v", test.profile, diags)
+			continue
+		}
+		if client.APIKey != test.wantKey || client.BaseURL != test.wantURL || client.ProjectID != test.wantProject {
+			t.Errorf("profile %q: got key %q, endpoint %q and project %q, want %q, %q and %q", test.profile,
+				client.APIKey, client.BaseURL, client.ProjectID, test.wantKey, test.wantURL, test.wantProject)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	envProfile    = "UPTRACE_PROFILE"
+	envConfigFile = "UPTRACE_CONFIG_FILE"
+
+	defaultProfile = "default"
+)
+
+var errProfileNotFound = errors.New("profile not found")
+
+// Keys recognized in a credentials profile.
+var profileKeys = map[string]bool{
+	"endpoint":   true,
+	"api_key":    true,
+	"project_id": true,
+}
+
+// credentialsFilePath returns the path of the shared credentials file,
+// UPTRACE_CONFIG_FILE or ~/.config/uptrace/credentials.
+func credentialsFilePath() (string, error) {
+	if p := os.Getenv(envConfigFile); p != "" {
+		return p, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("locating home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "uptrace", "credentials"), nil
+}
+
+// loadProfile reads the named profile from the shared credentials file. The
+// file uses INI syntax:
+//
+//	[staging]
+//	endpoint   = https://uptrace.staging.example.com
+//	api_key    = ...
+//	project_id = 1
+//
+// A missing file is reported with an error wrapping fs.ErrNotExist, a missing
+// profile with errProfileNotFound.
+func loadProfile(filename, name string) (map[string]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		profile map[string]string
+		current string
+		lineNo  int
+	)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("%s:%d: unterminated profile header %q", filename, lineNo, line)
+			}
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			if current == name && profile == nil {
+				profile = map[string]string{}
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected \"key = value\", got %q", filename, lineNo, line)
+		}
+		if current == "" {
+			return nil, fmt.Errorf("%s:%d: setting outside of a [profile] section", filename, lineNo)
+		}
+		if current != name {
+			continue
+		}
+
+		key = strings.TrimSpace(key)
+		if !profileKeys[key] {
+			return nil, fmt.Errorf("%s:%d: unknown setting %q in profile %q", filename, lineNo, key, name)
+		}
+		profile[key] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", filename, err)
+	}
+
+	if profile == nil {
+		return nil, fmt.Errorf("%w: %q in %s", errProfileNotFound, name, filename)
+	}
+	return profile, nil
+}
@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeCredentials writes a credentials file with contents to a temporary
+// directory and returns its path.
+func writeCredentials(t *testing.T, contents string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "credentials")
+	if err := os.WriteFile(filename, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestLoadProfile(t *testing.T) {
+	filename := writeCredentials(t, `
+# comments and blank lines are ignored
+[default]
+api_key = default-key
+
+; so are these
+[ staging ]
+endpoint   = https://uptrace.staging.example.com
+api_key    = "staging-key"
+project_id = 2
+`)
+
+	tests := []struct {
+		name string
+		want map[string]string
+	}{
+		{"default", map[string]string{"api_key": "default-key"}},
+		{"staging", map[string]string{
+			"endpoint":   "https://uptrace.staging.example.com",
+			"api_key":    "staging-key",
+			"project_id": "2",
+		}},
+	}
+	for _, test := range tests {
+		got, err := loadProfile(filename, test.name)
+		if err != nil {
+			t.Errorf("loadProfile(%q): %s", test.name, err)
+			continue
+		}
+		if !maps.Equal(got, test.want) {
+			t.Errorf("loadProfile(%q) = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestLoadProfileError(t *testing.T) {
+	_, err := loadProfile(filepath.Join(t.TempDir(), "missing"), "default")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("loadProfile of a missing file = %v, want fs.ErrNotExist", err)
+	}
+
+	_, err = loadProfile(writeCredentials(t, "[default]\napi_key = key\n"), "staging")
+	if !errors.Is(err, errProfileNotFound) {
+		t.Errorf("loadProfile of an unknown profile = %v, want errProfileNotFound", err)
+	}
+
+	tests := []struct {
+		contents string
+		err      string
+	}{
+		{"[default\napi_key = key\n", ":1: unterminated profile header"},
+		{"[default]\napi_key\n", `:2: expected "key = value"`},
+		{"api_key = key\n[default]\n", ":1: setting outside of a [profile] section"},
+		{"[default]\ntoken = key\n", `:2: unknown setting "token"`},
+	}
+	for _, test := range tests {
+		_, err := loadProfile(writeCredentials(t, test.contents), "default")
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("loadProfile(%q) = %v, want error containing %q", test.contents, err, test.err)
+		}
+	}
+}
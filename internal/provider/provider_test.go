@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	uptrace "github.com/persona-ae/terraform-provider-uptrace/internal/services"
+)
+
+func TestConfigureEnv(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs map[string]string
+		env   map[string]string
+
+		wantEndpoint, wantKey, wantProject string
+	}{
+		{
+			name: "environment",
+			env: map[string]string{
+				envEndpoint:  "https://env.example.com/",
+				envAPIKey:    "env-key",
+				envProjectID: "1",
+			},
+			wantEndpoint: "https://env.example.com",
+			wantKey:      "env-key",
+			wantProject:  "1",
+		},
+		{
+			name: "attributes win",
+			attrs: map[string]string{
+				"endpoint":   "https://attr.example.com",
+				"api_key":    "attr-key",
+				"project_id": "2",
+			},
+			env: map[string]string{
+				envEndpoint:  "https://env.example.com",
+				envAPIKey:    "env-key",
+				envProjectID: "1",
+			},
+			wantEndpoint: "https://attr.example.com",
+			wantKey:      "attr-key",
+			wantProject:  "2",
+		},
+		{
+			name:  "mixed",
+			attrs: map[string]string{"api_key": "attr-key"},
+			env: map[string]string{
+				envAPIKey:    "env-key",
+				envProjectID: "1",
+			},
+			wantEndpoint: uptrace.BaseURL,
+			wantKey:      "attr-key",
+			wantProject:  "1",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clearEnv(t)
+			for envVar, value := range test.env {
+				t.Setenv(envVar, value)
+			}
+
+			client, diags := configure(t, test.attrs)
+			if diags.HasError() {
+				t.Fatal(diags)
+			}
+			if client.BaseURL != test.wantEndpoint || client.APIKey != test.wantKey || client.ProjectID != test.wantProject {
+				t.Errorf("got endpoint %q, key %q and project %q, want %q, %q and %q",
+					client.BaseURL, client.APIKey, client.ProjectID, test.wantEndpoint, test.wantKey, test.wantProject)
+			}
+		})
+	}
+}
+
+func TestConfigureMissingCredentials(t *testing.T) {
+	clearEnv(t)
+
+	_, diags := configure(t, nil)
+	for _, attribute := range []string{"api_key", "project_id"} {
+		missing := slices.ContainsFunc(diags.Errors(), func(d diag.Diagnostic) bool {
+			return d.Summary() == "Missing Uptrace provider "+attribute
+		})
+		if !missing {
+			t.Errorf("got diagnostics %v, want %s to be reported missing", diags, attribute)
+		}
+	}
+}
+
+func TestConfigureUserAgent(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(envAppendUserAgent, "atlantis/0.28")
+
+	client, diags := configure(t, map[string]string{
+		"api_key":          "key",
+		"project_id":       "1",
+		"extra_user_agent": "ci/42",
+	})
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	if want := "terraform-provider-uptrace/test ci/42 atlantis/0.28"; client.UserAgent != want {
+		t.Errorf("got User-Agent %q, want %q", client.UserAgent, want)
+	}
+}
@@ -3,25 +3,35 @@ package resources
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 
-	"github.com/hashicorp/terraform-plugin-framework/attr"
-	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/persona-ae/terraform-provider-uptrace/internal/models"
 	uptrace "github.com/persona-ae/terraform-provider-uptrace/internal/services"
+	"github.com/persona-ae/terraform-provider-uptrace/internal/telemetry"
 	"github.com/persona-ae/terraform-provider-uptrace/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource                = &monitorResource{}
-	_ resource.ResourceWithConfigure   = &monitorResource{}
-	_ resource.ResourceWithImportState = &monitorResource{}
+	_ resource.Resource                   = &monitorResource{}
+	_ resource.ResourceWithConfigure      = &monitorResource{}
+	_ resource.ResourceWithImportState    = &monitorResource{}
+	_ resource.ResourceWithModifyPlan     = &monitorResource{}
+	_ resource.ResourceWithValidateConfig = &monitorResource{}
 )
 
 func NewMonitorResource() resource.Resource {
@@ -30,8 +40,9 @@ func NewMonitorResource() resource.Resource {
 
 // monitorResource is the resource implementation.
 type monitorResource struct {
-	// this client is set by the provider
-	client *uptrace.UptraceClient
+	// these are set by the provider
+	client   uptrace.MonitorAPI
+	defaults *models.TFMonitorDefaults
 }
 
 // Metadata returns the resource type name.
@@ -45,7 +56,24 @@ func (r *monitorResource) Metadata(ctx context.Context, req resource.MetadataReq
 func (r *monitorResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	tflog.Debug(ctx, "monitorResource.Schema", map[string]any{"req": req, "resp": resp})
 
-	resp.Schema = schema.Schema{
+	resp.Schema = monitorSchema()
+}
+
+// metricAliasRe matches the aliases metrics are referenced by in queries, eg.
+// $spans.
+var metricAliasRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// metricNameRe matches OpenTelemetry instrument names, eg.
+// uptrace_tracing_spans or system.cpu.time, and Prometheus recording rules,
+// eg. job:http_requests:rate5m.
+var metricNameRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_.\-/:]*$`)
+
+// monitorSchema returns the current schema of the resource. Increment its
+// version and add a state upgrader whenever the type of an attribute changes,
+// see UpgradeState.
+func monitorSchema() schema.Schema {
+	return schema.Schema{
+		Version:     1,
 		Description: "Manages a monitor.",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -61,52 +89,84 @@ func (r *monitorResource) Schema(ctx context.Context, req resource.SchemaRequest
 			},
 			"type": schema.StringAttribute{
 				Required:    true,
-				Description: "The monitor type ('metric' or 'error').",
+				Description: "The monitor type. " + utils.EnumDescription(uptrace.MonitorTypes),
+				Validators: []validator.String{
+					utils.OneOf(uptrace.MonitorTypes...),
+				},
 			},
 			"query": schema.StringAttribute{
-				Required:    true,
-				Description: "The monitor's query eg. \"perMin(sum($spans)) as spans\".",
+				Required: true,
+				Description: "The monitor's query eg. \"perMin(sum($spans)) as spans\". " +
+					"Every $alias it references must be declared in metrics.",
 			},
-			"metrics": schema.ListAttribute{
+			"metrics": schema.ListNestedAttribute{
 				Required:    true,
 				Description: "List of metrics to monitor eg. [{\"name\": \"uptrace_tracing_spans\", \"alias\": \"spans\"}].",
-				ElementType: types.ObjectType{
-					AttrTypes: map[string]attr.Type{
-						"name":  types.StringType,
-						"alias": types.StringType,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Required:    true,
+							Description: "The name of the metric, eg. uptrace_tracing_spans.",
+							Validators: []validator.String{
+								utils.Matches(metricNameRe, "must be a metric name such as uptrace_tracing_spans or system.cpu.time"),
+							},
+						},
+						"alias": schema.StringAttribute{
+							Required: true,
+							Description: "The alias the metric is referenced by in the query, without the leading $. " +
+								"Must be an identifier of letters, digits and underscores, unique within the monitor.",
+							Validators: []validator.String{
+								utils.Matches(metricAliasRe, "must be an identifier of letters, digits and underscores not starting with a digit"),
+							},
+						},
 					},
 				},
+				Validators: []validator.List{
+					utils.UniqueAttribute("alias"),
+				},
 			},
 			// begin optionals
 			"repeat_interval": schema.StringAttribute{
 				Computed:    true,
 				Optional:    true,
-				Description: "Notification repeat interval",
-				MarkdownDescription: `Notification repeat interval
+				Description: "Notification repeat interval. " + utils.EnumDescription(uptrace.RepeatIntervalStrategies),
+				MarkdownDescription: `Notification repeat interval. ` + utils.EnumDescription(uptrace.RepeatIntervalStrategies) + `
 By default, Uptrace uses adaptive interval to wait before sending a notification again.
 
 The interval starts from 15 minutes and doubles every 3 notifications, e.g. 15m, 15m, 15m, 30m, 30m, 30m, 1h...
 
 The max interval is 24 hours.
 `,
+				Validators: []validator.String{
+					utils.OneOf(uptrace.RepeatIntervalStrategies...),
+				},
 			},
 			"column_unit": schema.StringAttribute{
 				Computed:    true,
 				Optional:    true,
-				Description: "The unit of the metric in the selected column",
+				Description: "The unit of the metric in the selected column. " + utils.EnumDescription(uptrace.ColumnUnits),
+				Validators: []validator.String{
+					utils.OneOf(uptrace.ColumnUnits...),
+				},
 			},
 			"nulls_mode": schema.StringAttribute{
 				Computed:    true,
 				Optional:    true,
-				Description: "Nulls handling mode: allow, forbid, convert. The default is allow.",
+				Description: "Nulls handling mode. " + utils.EnumDescription(uptrace.NullsModes) + " The default is allow.",
+				Validators: []validator.String{
+					utils.OneOf(uptrace.NullsModes...),
+				},
 			},
 			"tolerance": schema.StringAttribute{
 				Computed:    true,
 				Optional:    true,
-				Description: "The tolerance of the automaticly triggered monitor (low, medium, or high).",
-				MarkdownDescription: `The tolerance of the automaticly triggered monitor (low, medium, or high).
-To reduce the number of alers, pick higher tolerance.
+				Description: "The tolerance of the automaticly triggered monitor. " + utils.EnumDescription(uptrace.Tolerances),
+				MarkdownDescription: `The tolerance of the automaticly triggered monitor. ` + utils.EnumDescription(uptrace.Tolerances) + `
+To reduce the number of alers, pick higher tolerance. Only used with ` + "`bounds_source = \"auto\"`" + `.
 `,
+				Validators: []validator.String{
+					utils.OneOf(uptrace.Tolerances...),
+				},
 			},
 			"notify_everyone_by_email": schema.BoolAttribute{
 				Computed:    true,
@@ -126,18 +186,18 @@ To reduce the number of alers, pick higher tolerance.
 			"min_allowed_value": schema.Float64Attribute{
 				Computed:    true,
 				Optional:    true,
-				Description: "Inclusive. Values lower than this are reported (At least min_allowed_value or max_allowed_value is required).",
+				Description: "Inclusive. Values lower than this are reported (At least min_allowed_value or max_allowed_value is required with manual bounds).",
 			},
 			"max_allowed_value": schema.Float64Attribute{
 				Computed:    true,
 				Optional:    true,
-				Description: "Inclusive. Values greater than this are reported (At least min_allowed_value or max_allowed_value is required).",
+				Description: "Inclusive. Values greater than this are reported (At least min_allowed_value or max_allowed_value is required with manual bounds).",
 			},
 			"min_allowed_flapping_value": schema.Float64Attribute{
 				Computed:    true,
 				Optional:    true,
 				Description: "Min allowed number",
-				MarkdownDescription: `Min allowed number
+				MarkdownDescription: `Min allowed number, at least min_allowed_value
 Flapping occures when the monitor triggers the same alert for a short period of time because the monitored value changes back and forth around the trigger point. To reduce the noise, you can configure additional conditions required to close the alert.
 For example, the filesystem utilization monitor may fluctuate from 0.89 to 0.9, causing the alert status to change constantly. By configuring the maximum allowed value to 0.85, the alert won't be closed until the value changes from 0.9 to 0.85.
 `,
@@ -146,7 +206,7 @@ For example, the filesystem utilization monitor may fluctuate from 0.89 to 0.9,
 				Computed:    true,
 				Optional:    true,
 				Description: "Max allowed number (trigger value: 500)",
-				MarkdownDescription: `Max allowed number (trigger value: 500)
+				MarkdownDescription: `Max allowed number (trigger value: 500), at most max_allowed_value
 Flapping occures when the monitor triggers the same alert for a short period of time because the monitored value changes back and forth around the trigger point. To reduce the noise, you can configure additional conditions required to close the alert.
 For example, the filesystem utilization monitor may fluctuate from 0.89 to 0.9, causing the alert status to change constantly. By configuring the maximum allowed value to 0.85, the alert won't be closed until the value changes from 0.9 to 0.85.
 `,
@@ -156,7 +216,7 @@ For example, the filesystem utilization monitor may fluctuate from 0.89 to 0.9,
 				Optional:    true,
 				Description: "Training period",
 				MarkdownDescription: `Training period
-Use smaller training periods for volatile values such as CPU usage.
+Use smaller training periods for volatile values such as CPU usage. Only used with ` + "`bounds_source = \"auto\"`" + `.
 `,
 			},
 			"time_offset": schema.Int32Attribute{
@@ -189,20 +249,31 @@ Use smaller training periods for volatile values such as CPU usage.
 			"bounds_source": schema.StringAttribute{
 				Computed:    true,
 				Optional:    true,
-				Description: "Bounds trigger source (manual or auto).",
-			},
-			// begin computed
-			"status": schema.StringAttribute{
-				Computed:    true,
-				Description: "The current status of the monitor.",
+				Description: "Bounds trigger source. " + utils.EnumDescription(uptrace.BoundsSources),
+				Validators: []validator.String{
+					utils.OneOf(uptrace.BoundsSources...),
+				},
 			},
 			"project_id": schema.Int32Attribute{
 				Computed:    true,
-				Description: "The ID of the project this monitor is associated with.",
+				Optional:    true,
+				Description: "The ID of the project this monitor is associated with. Defaults to the provider project_id. Changing it recreates the monitor.",
+				PlanModifiers: []planmodifier.Int32{
+					int32planmodifier.UseStateForUnknown(),
+					int32planmodifier.RequiresReplace(),
+				},
 			},
 			"column": schema.StringAttribute{
+				Computed: true,
+				Optional: true,
+				Description: "Column name to monitor, eg. spans. Must be one of the columns of the query: its name given with `as`, " +
+					"or else its expression as written, eg. `perMin(sum($spans))`. " +
+					"Required when the query has several columns, defaults to the only column otherwise.",
+			},
+			// begin computed
+			"status": schema.StringAttribute{
 				Computed:    true,
-				Description: "Column name to monitor, eg. spans.",
+				Description: "The current status of the monitor.",
 			},
 		},
 	}
@@ -216,20 +287,61 @@ func (r *monitorResource) Configure(ctx context.Context, req resource.ConfigureR
 	}
 
 	// extract the client from the provider data
-	client, ok := req.ProviderData.(*uptrace.UptraceClient)
+	data, ok := req.ProviderData.(*models.ProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *uptrace.UptraceClient, got: %T", req.ProviderData),
+			fmt.Sprintf("Expected *models.ProviderData, got: %T", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.defaults = data.MonitorDefaults
+}
+
+// ValidateConfig checks the rules Uptrace applies across attributes, see
+// utils.ValidateMonitorConfig.
+func (r *monitorResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config models.TFMonitorData
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	utils.ValidateMonitorConfig(config, &resp.Diagnostics)
+}
+
+// ModifyPlan merges the provider's monitor defaults underneath the planned
+// values and derives the column from the query, so the plan shows what will
+// be sent to Uptrace.
+func (r *monitorResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to do on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var config, plan models.TFMonitorData
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the defaults are only known once the provider is configured
+	if r.defaults != nil {
+		utils.ApplyMonitorDefaults(*r.defaults, config, &plan)
+	}
+	utils.DeriveMonitorColumn(config, &plan)
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
 }
 
 // Create a new resource.
 func (r *monitorResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx, span := telemetry.StartSpan(ctx, "uptrace_monitor.create")
+	defer endSpan(span, &resp.Diagnostics)
+
 	tflog.Debug(ctx, "monitorResource.Create", map[string]any{"req": req, "resp": resp})
 	var plan models.TFMonitorData
 
@@ -254,15 +366,16 @@ func (r *monitorResource) Create(ctx context.Context, req resource.CreateRequest
 
 	// Create new monitor
 	var response uptrace.MonitorResponse
-	err := r.client.CreateMonitor(ctx, monitor, &response)
+	projectID := r.projectID(plan.ProjectID)
+	span.SetAttributes(attribute.String("uptrace.project_id", projectID))
+	err := r.client.CreateMonitor(ctx, projectID, monitor, &response)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to create monitor",
-			fmt.Sprintf("Failed to create monitor: %s", err),
-		)
+		resp.Diagnostics.Append(utils.MonitorAPIErrorDiagnostics("Failed to create monitor", err)...)
 		return
 	}
 
+	span.SetAttributes(attribute.String("uptrace.monitor.id", strconv.Itoa(int(response.Monitor.ID))))
+
 	// log the response
 	tflog.Info(ctx, "CreateMonitor OK: %s", map[string]any{"response": response})
 
@@ -279,6 +392,9 @@ func (r *monitorResource) Create(ctx context.Context, req resource.CreateRequest
 
 // Read resource information.
 func (r *monitorResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx, span := telemetry.StartSpan(ctx, "uptrace_monitor.read")
+	defer endSpan(span, &resp.Diagnostics)
+
 	tflog.Debug(ctx, "monitorResource.Read", map[string]any{"req": req, "resp": resp})
 
 	// Get current state
@@ -292,7 +408,15 @@ func (r *monitorResource) Read(ctx context.Context, req resource.ReadRequest, re
 	// Get fresh state from uptrace
 	// Generate API request body from plan
 	var response uptrace.MonitorResponse
-	err := r.client.GetMonitorById(ctx, state.ID.ValueString(), &response)
+	projectID := r.projectID(state.ProjectID)
+	setMonitorAttributes(span, projectID, state.ID.ValueString())
+	err := r.client.GetMonitorById(ctx, projectID, state.ID.ValueString(), &response)
+	if uptrace.IsNotFound(err) {
+		// The monitor was deleted outside of Terraform, plan to recreate it.
+		tflog.Warn(ctx, "Monitor not found, removing from state", map[string]any{"id": state.ID.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get monitor",
@@ -315,6 +439,9 @@ func (r *monitorResource) Read(ctx context.Context, req resource.ReadRequest, re
 
 // Update resource information.
 func (r *monitorResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx, span := telemetry.StartSpan(ctx, "uptrace_monitor.update")
+	defer endSpan(span, &resp.Diagnostics)
+
 	tflog.Debug(ctx, "monitorResource.Update", map[string]any{"req": req, "resp": resp})
 
 	var plan models.TFMonitorData
@@ -335,12 +462,11 @@ func (r *monitorResource) Update(ctx context.Context, req resource.UpdateRequest
 	}
 
 	var response uptrace.MonitorResponse
-	err := r.client.UpdateMonitor(ctx, id, monitor, &response)
+	projectID := r.projectID(plan.ProjectID)
+	setMonitorAttributes(span, projectID, id)
+	err := r.client.UpdateMonitor(ctx, projectID, id, monitor, &response)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to update monitor",
-			fmt.Sprintf("Failed to update monitor: %s", err),
-		)
+		resp.Diagnostics.Append(utils.MonitorAPIErrorDiagnostics("Failed to update monitor", err)...)
 		return
 	}
 
@@ -360,6 +486,9 @@ func (r *monitorResource) Update(ctx context.Context, req resource.UpdateRequest
 
 // Delete resource information.
 func (r *monitorResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx, span := telemetry.StartSpan(ctx, "uptrace_monitor.delete")
+	defer endSpan(span, &resp.Diagnostics)
+
 	tflog.Debug(ctx, "monitorResource.Delete", map[string]any{"req": req, "resp": resp})
 
 	var state models.TFMonitorData
@@ -371,12 +500,11 @@ func (r *monitorResource) Delete(ctx context.Context, req resource.DeleteRequest
 	}
 
 	id := state.ID.ValueString()
-	err := r.client.DeleteMonitor(ctx, id)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to delete monitor",
-			fmt.Sprintf("Failed to delete monitor: %s", err),
-		)
+	projectID := r.projectID(state.ProjectID)
+	setMonitorAttributes(span, projectID, id)
+	err := r.client.DeleteMonitor(ctx, projectID, id)
+	if err != nil && !uptrace.IsNotFound(err) {
+		resp.Diagnostics.Append(utils.MonitorAPIErrorDiagnostics("Failed to delete monitor", err)...)
 		return
 	}
 
@@ -385,13 +513,32 @@ func (r *monitorResource) Delete(ctx context.Context, req resource.DeleteRequest
 	tflog.Info(ctx, "DeleteMonitor OK", map[string]any{"response": response})
 }
 
+// ImportState imports a monitor by its id, or by "<project_id>/<id>" for
+// monitors outside the provider's default project.
 func (r *monitorResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	ctx, span := telemetry.StartSpan(ctx, "uptrace_monitor.import")
+	defer endSpan(span, &resp.Diagnostics)
+
 	tflog.Debug(ctx, "monitorResource.ImportState", map[string]any{"req": req, "resp": resp})
 
-	// Get fresh state from Uptrace
+	projectID := r.client.DefaultProjectID()
 	id := req.ID
+	if parts := strings.Split(req.ID, "/"); len(parts) == 2 {
+		projectID, id = parts[0], parts[1]
+	}
+	if projectID == "" || id == "" {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("Expected \"<id>\" or \"<project_id>/<id>\", got: %q", req.ID),
+		)
+		return
+	}
+
+	setMonitorAttributes(span, projectID, id)
+
+	// Get fresh state from Uptrace
 	var response uptrace.MonitorResponse
-	err := r.client.GetMonitorById(ctx, id, &response)
+	err := r.client.GetMonitorById(ctx, projectID, id, &response)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get monitor",
@@ -400,7 +547,7 @@ func (r *monitorResource) ImportState(ctx context.Context, req resource.ImportSt
 		return
 	}
 
-	// Save data into Terraform state
+	// Save data into Terraform state, including the id and project_id
 	var state models.TFMonitorData
 	diags := utils.OverlayMonitorOnTFMonitorData(ctx, response.Monitor, &state)
 	resp.Diagnostics.Append(diags...)
@@ -409,7 +556,30 @@ func (r *monitorResource) ImportState(ctx context.Context, req resource.ImportSt
 	}
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
+}
+
+// projectID returns the project a monitor lives in, falling back to the
+// provider's default project when the resource does not set one.
+func (r *monitorResource) projectID(value types.Int32) string {
+	if value.IsNull() || value.IsUnknown() {
+		return r.client.DefaultProjectID()
+	}
+	return strconv.Itoa(int(value.ValueInt32()))
+}
+
+// setMonitorAttributes records the monitor an operation works on in its span.
+func setMonitorAttributes(span trace.Span, projectID, id string) {
+	span.SetAttributes(
+		attribute.String("uptrace.project_id", projectID),
+		attribute.String("uptrace.monitor.id", id),
+	)
+}
 
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+// endSpan ends the span of a resource operation, marking it as failed when
+// the operation reported errors.
+func endSpan(span trace.Span, diags *diag.Diagnostics) {
+	if errs := diags.Errors(); len(errs) > 0 {
+		span.SetStatus(codes.Error, errs[0].Summary())
+	}
+	span.End()
 }
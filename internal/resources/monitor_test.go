@@ -0,0 +1,503 @@
+package resources_test
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/persona-ae/terraform-provider-uptrace/internal/fake"
+	"github.com/persona-ae/terraform-provider-uptrace/internal/provider"
+	uptrace "github.com/persona-ae/terraform-provider-uptrace/internal/services"
+	"github.com/persona-ae/terraform-provider-uptrace/internal/telemetry"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const (
+	testAPIKey    = "test-api-key"
+	testProjectID = "1"
+)
+
+var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"uptrace": providerserver.NewProtocol6WithError(provider.NewWithVCR("test")()),
+}
+
+// testAccEnv is the Uptrace API an acceptance test runs against. By default
+// that is a fake server started for the test. With UPTRACE_VCR_MODE=record
+// the test runs against the instance configured with the UPTRACE_*
+// environment variables and records its interactions to
+// testdata/cassettes/<test>.json, with UPTRACE_VCR_MODE=replay it is served
+// from that cassette. server is nil in both VCR modes.
+type testAccEnv struct {
+	server *fake.Server
+	attrs  map[string]string
+
+	// monitorDefaults is the body of the provider's monitor_defaults block,
+	// which is omitted when empty.
+	monitorDefaults string
+}
+
+func newTestAccEnv(t *testing.T) testAccEnv {
+	t.Helper()
+	t.Setenv(uptrace.EnvVCRCassette, filepath.Join("testdata", "cassettes", t.Name()+".json"))
+
+	switch os.Getenv(uptrace.EnvVCRMode) {
+	case uptrace.VCRModeRecord:
+		return testAccEnv{attrs: map[string]string{}}
+	case uptrace.VCRModeReplay:
+		return testAccEnv{attrs: map[string]string{
+			"endpoint":   "https://uptrace.invalid",
+			"api_key":    testAPIKey,
+			"project_id": testProjectID,
+		}}
+	}
+
+	server := fake.NewServer(testAPIKey, testProjectID)
+	t.Cleanup(server.Close)
+	return testAccEnv{
+		server: server,
+		attrs: map[string]string{
+			"endpoint":   server.URL,
+			"api_key":    testAPIKey,
+			"project_id": testProjectID,
+		},
+	}
+}
+
+// skipUnlessFake skips tests that manipulate the fake backend directly.
+func (e testAccEnv) skipUnlessFake(t *testing.T) {
+	t.Helper()
+	if e.server == nil {
+		t.Skipf("%s requires the fake Uptrace server", t.Name())
+	}
+}
+
+// withAttr returns a copy of the environment with the provider attribute name
+// set to value.
+func (e testAccEnv) withAttr(name, value string) testAccEnv {
+	e.attrs = maps.Clone(e.attrs)
+	e.attrs[name] = value
+	return e
+}
+
+// withMonitorDefaults returns a copy of the environment with the provider's
+// monitor_defaults block set to body.
+func (e testAccEnv) withMonitorDefaults(body string) testAccEnv {
+	e.monitorDefaults = body
+	return e
+}
+
+// providerConfig renders the provider block.
+func (e testAccEnv) providerConfig() string {
+	names := make([]string, 0, len(e.attrs))
+	for k := range e.attrs {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("provider \"uptrace\" {\n")
+	for _, k := range names {
+		fmt.Fprintf(&b, "  %s = %q\n", k, e.attrs[k])
+	}
+	if e.monitorDefaults != "" {
+		fmt.Fprintf(&b, "\n  monitor_defaults {\n%s  }\n", e.monitorDefaults)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// testMonitorAttrs are the attributes of the monitor rendered by
+// monitorConfig, as attribute and expression pairs.
+var testMonitorAttrs = []string{
+	"name", `"spans"`,
+	"type", `"metric"`,
+	"query", `"perMin(sum($spans)) as spans"`,
+	"metrics", `[{ name = "uptrace_tracing_spans", alias = "spans" }]`,
+	"max_allowed_value", "100",
+}
+
+// monitorConfig renders the provider block and the uptrace_monitor.test
+// resource, with attributes overridden or added as attribute and expression
+// pairs, eg. "query", `"sum($spans)"`. An empty expression removes the
+// attribute.
+func (e testAccEnv) monitorConfig(overrides ...string) string {
+	var names []string
+	values := map[string]string{}
+	for _, attrs := range [][]string{testMonitorAttrs, overrides} {
+		for i := 0; i+1 < len(attrs); i += 2 {
+			if _, ok := values[attrs[i]]; !ok {
+				names = append(names, attrs[i])
+			}
+			values[attrs[i]] = attrs[i+1]
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(e.providerConfig())
+	b.WriteString("\nresource \"uptrace_monitor\" \"test\" {\n")
+	for _, name := range names {
+		if values[name] != "" {
+			fmt.Fprintf(&b, "  %s = %s\n", name, values[name])
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func (e testAccEnv) checkMonitorDestroyed(s *terraform.State) error {
+	if e.server == nil {
+		return nil
+	}
+	if monitors := e.server.Backend.Monitors(testProjectID); len(monitors) != 0 {
+		return fmt.Errorf("expected all monitors to be deleted, %d left", len(monitors))
+	}
+	return nil
+}
+
+func TestAccMonitorResource(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             env.checkMonitorDestroyed,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: env.monitorConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("uptrace_monitor.test", "id"),
+					resource.TestCheckResourceAttrSet("uptrace_monitor.test", "project_id"),
+					resource.TestCheckResourceAttr("uptrace_monitor.test", "name", "spans"),
+					resource.TestCheckResourceAttr("uptrace_monitor.test", "status", "active"),
+					resource.TestCheckResourceAttr("uptrace_monitor.test", "max_allowed_value", "100"),
+					resource.TestCheckResourceAttr("uptrace_monitor.test", "metrics.#", "1"),
+					resource.TestCheckResourceAttr("uptrace_monitor.test", "metrics.0.alias", "spans"),
+					resource.TestCheckResourceAttr("uptrace_monitor.test", "column", "spans"),
+					resource.TestCheckResourceAttr("uptrace_monitor.test", "nulls_mode", "allow"),
+					resource.TestCheckResourceAttr("uptrace_monitor.test", "grouping_interval", "60000"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "uptrace_monitor.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// ImportState testing with an explicit project
+			{
+				ResourceName: "uptrace_monitor.test",
+				ImportState:  true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					rs := s.RootModule().Resources["uptrace_monitor.test"].Primary
+					return rs.Attributes["project_id"] + "/" + rs.ID, nil
+				},
+				ImportStateVerify: true,
+			},
+			// Update and Read testing
+			{
+				Config: env.monitorConfig("name", `"spans per minute"`, "max_allowed_value", "200"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("uptrace_monitor.test", "name", "spans per minute"),
+					resource.TestCheckResourceAttr("uptrace_monitor.test", "max_allowed_value", "200"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccMonitorResource_disappears(t *testing.T) {
+	env := newTestAccEnv(t)
+	env.skipUnlessFake(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             env.checkMonitorDestroyed,
+		Steps: []resource.TestStep{
+			{
+				Config: env.monitorConfig(),
+				Check: func(s *terraform.State) error {
+					id := s.RootModule().Resources["uptrace_monitor.test"].Primary.ID
+					return env.server.Backend.DeleteMonitor(context.Background(), testProjectID, id)
+				},
+				ExpectNonEmptyPlan: true,
+			},
+			// the 404 on read removes the monitor from the state
+			{
+				RefreshState: true,
+				Check: func(s *terraform.State) error {
+					if _, ok := s.RootModule().Resources["uptrace_monitor.test"]; ok {
+						return fmt.Errorf("expected the deleted monitor to be removed from the state")
+					}
+					return nil
+				},
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccMonitorResource_invalid(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      env.monitorConfig("type", `"metrc"`),
+				ExpectError: regexp.MustCompile(`Did you\s+mean "metric"\?`),
+			},
+		},
+	})
+}
+
+func TestAccMonitorResource_invalidBounds(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      env.monitorConfig("max_allowed_value", ""),
+				ExpectError: regexp.MustCompile(`Missing monitor bounds`),
+			},
+			{
+				Config:      env.monitorConfig("max_allowed_flapping_value", "150"),
+				ExpectError: regexp.MustCompile(`Invalid monitor flapping bounds`),
+			},
+			{
+				Config:      env.monitorConfig("tolerance", `"high"`),
+				ExpectError: regexp.MustCompile(`only used with automatic bounds`),
+			},
+			{
+				// Unknown values are only checked once known.
+				Config: env.monitorConfig("tolerance", "terraform_data.tolerance.output") + `
+resource "terraform_data" "tolerance" {}
+`,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccMonitorResource_invalidMetrics(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      env.monitorConfig("metrics", `[{ name = "uptrace_tracing_spans", alias = "$spans" }]`),
+				ExpectError: regexp.MustCompile(`must be an identifier`),
+			},
+			{
+				Config: env.monitorConfig("metrics", `[
+    { name = "uptrace_tracing_spans", alias = "spans" },
+    { name = "uptrace_tracing_events", alias = "spans" },
+  ]`),
+				ExpectError: regexp.MustCompile(`Duplicate Attribute Value`),
+			},
+			{
+				// Prometheus recording rules are valid metric names.
+				Config:             env.monitorConfig("metrics", `[{ name = "job:http_requests:rate5m", alias = "spans" }]`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccMonitorResource_invalidQuery(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      env.monitorConfig("query", `"perMin(sum($span)) as spans"`),
+				ExpectError: regexp.MustCompile(`Did you\s+mean\s+\$spans\?`),
+			},
+			{
+				// Unknown functions are only warned about.
+				Config:             env.monitorConfig("query", strconv.Quote(`top3(perMin(summ($spans))) as spans | where service.name ~ "^api" and host.name exists`)),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config:      env.monitorConfig("query", `"perMin(sum($spans) as spans"`),
+				ExpectError: regexp.MustCompile(`Invalid monitor query`),
+			},
+			{
+				Config:      env.monitorConfig("query", `"perMin(sum($spans)) as spans, sum($spans) as spans"`),
+				ExpectError: regexp.MustCompile(`several columns named "spans"`),
+			},
+		},
+	})
+}
+
+func TestAccMonitorResource_column(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	config := func(column string) string {
+		return env.monitorConfig(
+			"name", `"error rate"`,
+			"query", `"perMin(sum($errors)) as errors, perMin(sum($spans)) as spans"`,
+			"metrics", `[
+    { name = "uptrace_tracing_errors", alias = "errors" },
+    { name = "uptrace_tracing_spans", alias = "spans" },
+  ]`,
+			"column", column,
+		)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             env.checkMonitorDestroyed,
+		Steps: []resource.TestStep{
+			{
+				Config:      config(""),
+				ExpectError: regexp.MustCompile(`Missing monitor column`),
+			},
+			{
+				Config:      config(`"error"`),
+				ExpectError: regexp.MustCompile(`Did you\s+mean\s+"errors"\?`),
+			},
+			{
+				Config: config(`"errors"`),
+				Check:  resource.TestCheckResourceAttr("uptrace_monitor.test", "column", "errors"),
+			},
+		},
+	})
+}
+
+func TestAccMonitorResource_monitorDefaults(t *testing.T) {
+	env := newTestAccEnv(t).withMonitorDefaults(`    nulls_mode        = "convert"
+    grouping_interval = 120000
+    check_num_point   = 5
+`)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             env.checkMonitorDestroyed,
+		Steps: []resource.TestStep{
+			{
+				Config: env.monitorConfig("nulls_mode", `"forbid"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("uptrace_monitor.test", "nulls_mode", "forbid"),
+					resource.TestCheckResourceAttr("uptrace_monitor.test", "grouping_interval", "120000"),
+					resource.TestCheckResourceAttr("uptrace_monitor.test", "check_num_point", "5"),
+				),
+			},
+			// unchanged defaults plan nothing
+			{
+				Config:   env.monitorConfig("nulls_mode", `"forbid"`),
+				PlanOnly: true,
+			},
+			{
+				Config: env.monitorConfig(),
+				Check:  resource.TestCheckResourceAttr("uptrace_monitor.test", "nulls_mode", "convert"),
+			},
+		},
+	})
+}
+
+func TestAccMonitorResource_badAPIKey(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      env.withAttr("api_key", "wrong").monitorConfig(),
+				ExpectError: regexp.MustCompile(`Invalid Uptrace API key`),
+			},
+		},
+	})
+}
+
+func TestAccMonitorResource_tracing(t *testing.T) {
+	env := newTestAccEnv(t)
+
+	exporter := tracetest.NewInMemoryExporter()
+	telemetry.InitWithExporter(exporter)
+	t.Cleanup(func() { _ = telemetry.Shutdown(context.Background()) })
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             env.checkMonitorDestroyed,
+		Steps: []resource.TestStep{
+			{
+				Config: env.monitorConfig(),
+				Check: func(s *terraform.State) error {
+					id := s.RootModule().Resources["uptrace_monitor.test"].Primary.ID
+
+					var create, post bool
+					for _, span := range exporter.GetSpans() {
+						attrs := map[string]string{}
+						for _, kv := range span.Attributes {
+							attrs[string(kv.Key)] = kv.Value.Emit()
+						}
+						switch span.Name {
+						case "uptrace_monitor.create":
+							create = attrs["uptrace.monitor.id"] == id && attrs["uptrace.project_id"] == testProjectID
+						case "uptrace POST":
+							post = attrs["http.response.status_code"] != "" && attrs["http.request.resend_count"] == "0" &&
+								span.Parent.TraceID().IsValid()
+						}
+					}
+					if !create || !post {
+						return fmt.Errorf("expected create and POST spans, got %d spans", len(exporter.GetSpans()))
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+// TestAccMonitorResource_client runs the resource on a client injected with
+// provider.NewWithClient, which is configured like the HTTP client.
+func TestAccMonitorResource_client(t *testing.T) {
+	backend := fake.NewClient(testProjectID)
+	factories := map[string]func() (tfprotov6.ProviderServer, error){
+		"uptrace": providerserver.NewProtocol6WithError(provider.NewWithClient("test", backend)()),
+	}
+	env := testAccEnv{attrs: map[string]string{
+		"api_key":    testAPIKey,
+		"project_id": testProjectID,
+	}}
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: factories,
+		Steps: []resource.TestStep{
+			{
+				Config:      env.withAttr("project_id", "unknown").monitorConfig(),
+				ExpectError: regexp.MustCompile(`Invalid Uptrace project`),
+			},
+			{
+				Config:      env.withAttr("read_only", "true").monitorConfig(),
+				ExpectError: regexp.MustCompile(`Provider is read-only`),
+			},
+			{
+				Config: env.monitorConfig(),
+				Check: func(s *terraform.State) error {
+					if monitors := backend.Monitors(testProjectID); len(monitors) != 1 || monitors[0].Name != "spans" {
+						return fmt.Errorf("expected the monitor in the injected client, got %v", monitors)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
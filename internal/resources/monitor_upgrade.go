@@ -0,0 +1,79 @@
+package resources
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/persona-ae/terraform-provider-uptrace/internal/models"
+)
+
+var _ resource.ResourceWithUpgradeState = &monitorResource{}
+
+// UpgradeState migrates the state written by earlier versions of the
+// resource to the current schema.
+func (r *monitorResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	schemaV0 := monitorSchemaV0()
+	return map[int64]resource.StateUpgrader{
+		// Version 0 declared metrics as a list of plain objects. The nested
+		// attribute has the same type, so the state carries over as is.
+		0: {
+			PriorSchema: &schemaV0,
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				var state models.TFMonitorData
+				resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+				resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+			},
+		},
+	}
+}
+
+// monitorSchemaV0 returns version 0 of the schema, which declared metrics as
+// a list of plain objects. It is frozen: only the types of its attributes
+// matter to read prior state, so descriptions and validators are left out.
+func monitorSchemaV0() schema.Schema {
+	return schema.Schema{
+		Version: 0,
+		Attributes: map[string]schema.Attribute{
+			"id":    schema.StringAttribute{Computed: true},
+			"name":  schema.StringAttribute{Required: true},
+			"type":  schema.StringAttribute{Required: true},
+			"query": schema.StringAttribute{Required: true},
+			"metrics": schema.ListAttribute{
+				Required: true,
+				ElementType: types.ObjectType{
+					AttrTypes: map[string]attr.Type{
+						"name":  types.StringType,
+						"alias": types.StringType,
+					},
+				},
+			},
+			"repeat_interval":            schema.StringAttribute{Optional: true, Computed: true},
+			"column_unit":                schema.StringAttribute{Optional: true, Computed: true},
+			"nulls_mode":                 schema.StringAttribute{Optional: true, Computed: true},
+			"tolerance":                  schema.StringAttribute{Optional: true, Computed: true},
+			"notify_everyone_by_email":   schema.BoolAttribute{Optional: true, Computed: true},
+			"min_dev_value":              schema.Float64Attribute{Optional: true, Computed: true},
+			"min_dev_fraction":           schema.Float64Attribute{Optional: true, Computed: true},
+			"min_allowed_value":          schema.Float64Attribute{Optional: true, Computed: true},
+			"max_allowed_value":          schema.Float64Attribute{Optional: true, Computed: true},
+			"min_allowed_flapping_value": schema.Float64Attribute{Optional: true, Computed: true},
+			"max_allowed_flapping_value": schema.Float64Attribute{Optional: true, Computed: true},
+			"training_period":            schema.Int32Attribute{Optional: true, Computed: true},
+			"time_offset":                schema.Int32Attribute{Optional: true, Computed: true},
+			"grouping_interval":          schema.Int32Attribute{Optional: true, Computed: true},
+			"check_num_point":            schema.Int32Attribute{Optional: true, Computed: true},
+			"team_ids":                   schema.ListAttribute{ElementType: types.Int32Type, Optional: true, Computed: true},
+			"channel_ids":                schema.ListAttribute{ElementType: types.Int32Type, Optional: true, Computed: true},
+			"bounds_source":              schema.StringAttribute{Optional: true, Computed: true},
+			"project_id":                 schema.Int32Attribute{Optional: true, Computed: true},
+			"status":                     schema.StringAttribute{Computed: true},
+			"column":                     schema.StringAttribute{Computed: true},
+		},
+	}
+}
@@ -0,0 +1,89 @@
+package resources_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/persona-ae/terraform-provider-uptrace/internal/provider"
+)
+
+// TestMonitorResource_upgradeStateV0 upgrades the state written by version 0
+// of the schema, as saved by Terraform.
+func TestMonitorResource_upgradeStateV0(t *testing.T) {
+	ctx := context.Background()
+	raw, err := os.ReadFile(filepath.Join("testdata", "monitor_state_v0.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := providerserver.NewProtocol6WithError(provider.New("test")())()
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemas, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := schemas.ResourceSchemas["uptrace_monitor"]
+	if schema.Version != 1 {
+		t.Fatalf("schema version is %d, add a test for the upgrade to it", schema.Version)
+	}
+
+	resp, err := server.UpgradeResourceState(ctx, &tfprotov6.UpgradeResourceStateRequest{
+		TypeName: "uptrace_monitor",
+		Version:  0,
+		RawState: &tfprotov6.RawState{JSON: raw},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range resp.Diagnostics {
+		t.Errorf("%s: %s", d.Summary, d.Detail)
+	}
+	if t.Failed() {
+		return
+	}
+
+	state, err := resp.UpgradedState.Unmarshal(schema.ValueType())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var attrs map[string]tftypes.Value
+	if err := state.As(&attrs); err != nil {
+		t.Fatal(err)
+	}
+	var id, query string
+	var metrics []tftypes.Value
+	if err := attrs["id"].As(&id); err != nil {
+		t.Fatal(err)
+	}
+	if err := attrs["query"].As(&query); err != nil {
+		t.Fatal(err)
+	}
+	if err := attrs["metrics"].As(&metrics); err != nil {
+		t.Fatal(err)
+	}
+	if id != "1" || query != "perMin(sum($spans)) as spans" || len(metrics) != 1 {
+		t.Fatalf("upgraded state has id %q, query %q and %d metrics", id, query, len(metrics))
+	}
+
+	var metric map[string]tftypes.Value
+	var name, alias string
+	if err := metrics[0].As(&metric); err != nil {
+		t.Fatal(err)
+	}
+	if err := metric["name"].As(&name); err != nil {
+		t.Fatal(err)
+	}
+	if err := metric["alias"].As(&alias); err != nil {
+		t.Fatal(err)
+	}
+	if name != "uptrace_tracing_spans" || alias != "spans" {
+		t.Errorf("upgraded metric is %q as %q, want %q as %q", name, alias, "uptrace_tracing_spans", "spans")
+	}
+}
@@ -0,0 +1,22 @@
+package uptrace
+
+import "context"
+
+// MonitorAPI covers the monitor operations of the Uptrace API used by the
+// resources. It is implemented by UptraceClient and by the in-memory fake in
+// internal/fake, which lets the provider run without a real Uptrace.
+type MonitorAPI interface {
+	// DefaultProjectID returns the project used by callers that do not
+	// specify one.
+	DefaultProjectID() string
+
+	// GetMonitors returns a single page of monitors, see ListMonitors to
+	// iterate over all of them.
+	GetMonitors(ctx context.Context, projectID string, opts ListMonitorsOptions, out *GetMonitorsResponse) error
+	GetMonitorById(ctx context.Context, projectID, id string, out *MonitorResponse) error
+	CreateMonitor(ctx context.Context, projectID string, req Monitor, out *MonitorResponse) error
+	UpdateMonitor(ctx context.Context, projectID, id string, req Monitor, out *MonitorResponse) error
+	DeleteMonitor(ctx context.Context, projectID, id string) error
+}
+
+var _ MonitorAPI = &UptraceClient{}
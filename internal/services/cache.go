@@ -0,0 +1,168 @@
+package uptrace
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultCacheTTL is how long a cached monitor list is used to serve reads.
+const DefaultCacheTTL = 5 * time.Minute
+
+// CachingClient serves GetMonitorById from the monitor list of the project,
+// fetched once with ListMonitors and kept for TTL, so that refreshing many
+// monitors costs a single request instead of one per monitor. Concurrent
+// fetches of the same list are deduplicated. Writes through the client drop
+// the cached list of the project.
+//
+// Monitors missing from the cached list are fetched individually, so that
+// reads never report a monitor as deleted based on stale data.
+type CachingClient struct {
+	MonitorAPI
+
+	// TTL is how long a fetched list is used. Zero disables caching.
+	TTL time.Duration
+
+	mu       sync.Mutex
+	projects map[string]*cachedProject
+	group    singleflight.Group
+}
+
+type cachedProject struct {
+	// generation is incremented on every write, so that a list fetched
+	// concurrently with a write is not stored.
+	generation int
+	monitors   map[string]Monitor
+	fetchedAt  time.Time
+}
+
+var _ MonitorAPI = &CachingClient{}
+
+// NewCachingClient returns a CachingClient caching the monitor lists of next
+// for ttl.
+func NewCachingClient(next MonitorAPI, ttl time.Duration) *CachingClient {
+	return &CachingClient{
+		MonitorAPI: next,
+		TTL:        ttl,
+		projects:   make(map[string]*cachedProject),
+	}
+}
+
+func (c *CachingClient) GetMonitorById(ctx context.Context, projectID, id string, out *MonitorResponse) error {
+	if c.TTL <= 0 {
+		return c.MonitorAPI.GetMonitorById(ctx, projectID, id, out)
+	}
+
+	monitors, err := c.list(ctx, projectID)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// The list may be unavailable to the API key while the monitor
+		// itself is not, fall back to the single request.
+		tflog.Debug(ctx, "Listing monitors failed, reading monitor directly", map[string]any{
+			"project_id": projectID,
+			"error":      err.Error(),
+		})
+		return c.MonitorAPI.GetMonitorById(ctx, projectID, id, out)
+	}
+
+	monitor, ok := monitors[id]
+	if !ok {
+		return c.MonitorAPI.GetMonitorById(ctx, projectID, id, out)
+	}
+	out.Monitor = monitor
+	return nil
+}
+
+func (c *CachingClient) CreateMonitor(ctx context.Context, projectID string, req Monitor, out *MonitorResponse) error {
+	defer c.invalidate(projectID)
+	return c.MonitorAPI.CreateMonitor(ctx, projectID, req, out)
+}
+
+func (c *CachingClient) UpdateMonitor(ctx context.Context, projectID, id string, req Monitor, out *MonitorResponse) error {
+	defer c.invalidate(projectID)
+	return c.MonitorAPI.UpdateMonitor(ctx, projectID, id, req, out)
+}
+
+func (c *CachingClient) DeleteMonitor(ctx context.Context, projectID, id string) error {
+	defer c.invalidate(projectID)
+	return c.MonitorAPI.DeleteMonitor(ctx, projectID, id)
+}
+
+// list returns the monitors of the project by id, fetching them when they are
+// not cached or the cached list has expired.
+func (c *CachingClient) list(ctx context.Context, projectID string) (map[string]Monitor, error) {
+	c.mu.Lock()
+	project := c.project(projectID)
+	if project.monitors != nil && time.Since(project.fetchedAt) < c.TTL {
+		monitors := project.monitors
+		c.mu.Unlock()
+		return monitors, nil
+	}
+	generation := project.generation
+	c.mu.Unlock()
+
+	// The fetch is shared by every caller, so it must not be canceled with
+	// the context of the caller that started it. Each caller stops waiting
+	// when its own context is done.
+	key := projectID + "/" + strconv.Itoa(generation)
+	ch := c.group.DoChan(key, func() (any, error) {
+		return c.fetch(context.WithoutCancel(ctx), projectID, generation)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-ch:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(map[string]Monitor), nil
+	}
+}
+
+// fetch lists the monitors of the project and caches them, unless the
+// project was written to since generation.
+func (c *CachingClient) fetch(ctx context.Context, projectID string, generation int) (map[string]Monitor, error) {
+	fetchedAt := time.Now()
+
+	monitors := make(map[string]Monitor)
+	for monitor, err := range ListMonitors(ctx, c.MonitorAPI, projectID, ListMonitorsOptions{}) {
+		if err != nil {
+			return nil, err
+		}
+		monitors[strconv.Itoa(int(monitor.ID))] = monitor
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if project := c.project(projectID); project.generation == generation {
+		project.monitors = monitors
+		project.fetchedAt = fetchedAt
+	}
+	return monitors, nil
+}
+
+// invalidate drops the cached list of the project.
+func (c *CachingClient) invalidate(projectID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	project := c.project(projectID)
+	project.generation++
+	project.monitors = nil
+}
+
+// project returns the cache entry of the project. c.mu must be held.
+func (c *CachingClient) project(projectID string) *cachedProject {
+	project, ok := c.projects[projectID]
+	if !ok {
+		project = &cachedProject{}
+		c.projects[projectID] = project
+	}
+	return project
+}
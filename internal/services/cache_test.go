@@ -0,0 +1,187 @@
+package uptrace_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/persona-ae/terraform-provider-uptrace/internal/fake"
+	uptrace "github.com/persona-ae/terraform-provider-uptrace/internal/services"
+)
+
+// newTestCache returns a caching client over a fake backend holding n error
+// monitors in project 1, with ids 1 to n.
+func newTestCache(t *testing.T, n int) (*uptrace.CachingClient, *countingAPI) {
+	t.Helper()
+	backend := fake.NewClient("1")
+	for i := range n {
+		monitor := uptrace.Monitor{Name: fmt.Sprintf("monitor %d", i+1), Type: "error"}
+		if err := backend.CreateMonitor(context.Background(), "1", monitor, &uptrace.MonitorResponse{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	api := &countingAPI{MonitorAPI: backend}
+	return uptrace.NewCachingClient(api, time.Hour), api
+}
+
+// readName reads the monitor id through cache and returns its name.
+func readName(t *testing.T, cache *uptrace.CachingClient, id string) string {
+	t.Helper()
+	var out uptrace.MonitorResponse
+	if err := cache.GetMonitorById(context.Background(), "1", id, &out); err != nil {
+		t.Fatalf("GetMonitorById(%s): %s", id, err)
+	}
+	return out.Monitor.Name
+}
+
+func TestCachingClient(t *testing.T) {
+	cache, api := newTestCache(t, 3)
+
+	for _, id := range []string{"1", "2", "3", "1"} {
+		if got, want := readName(t, cache, id), "monitor "+id; got != want {
+			t.Errorf("read %q, want %q", got, want)
+		}
+	}
+	if lists, gets := api.counts(); lists != 1 || gets != 0 {
+		t.Errorf("made %d listings and %d reads, want 1 and 0", lists, gets)
+	}
+}
+
+func TestCachingClientDedupe(t *testing.T) {
+	cache, api := newTestCache(t, 3)
+	api.onList = func(context.Context) { time.Sleep(10 * time.Millisecond) }
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			readName(t, cache, fmt.Sprint(i%3+1))
+		}()
+	}
+	wg.Wait()
+
+	if lists, gets := api.counts(); lists != 1 || gets != 0 {
+		t.Errorf("made %d listings and %d reads, want 1 and 0", lists, gets)
+	}
+}
+
+func TestCachingClientInvalidate(t *testing.T) {
+	ctx := context.Background()
+	cache, api := newTestCache(t, 2)
+	readName(t, cache, "1")
+
+	var out uptrace.MonitorResponse
+	if err := cache.CreateMonitor(ctx, "1", uptrace.Monitor{Name: "monitor 3", Type: "error"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if got := readName(t, cache, "3"); got != "monitor 3" {
+		t.Errorf("read %q after create, want %q", got, "monitor 3")
+	}
+
+	if err := cache.UpdateMonitor(ctx, "1", "1", uptrace.Monitor{Name: "renamed", Type: "error"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if got := readName(t, cache, "1"); got != "renamed" {
+		t.Errorf("read %q after update, want %q", got, "renamed")
+	}
+
+	if err := cache.DeleteMonitor(ctx, "1", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.GetMonitorById(ctx, "1", "2", &out); !uptrace.IsNotFound(err) {
+		t.Errorf("read after delete = %v, want a not found error", err)
+	}
+
+	// Every write drops the list, the deleted monitor is read directly.
+	if lists, gets := api.counts(); lists != 4 || gets != 1 {
+		t.Errorf("made %d listings and %d reads, want 4 and 1", lists, gets)
+	}
+}
+
+func TestCachingClientWriteDuringList(t *testing.T) {
+	ctx := context.Background()
+	cache, api := newTestCache(t, 1)
+
+	var once sync.Once
+	listing, release := make(chan struct{}), make(chan struct{})
+	api.onList = func(context.Context) {
+		once.Do(func() {
+			close(listing)
+			<-release
+		})
+	}
+	done := make(chan string)
+	go func() { done <- readName(t, cache, "1") }()
+
+	// Update the monitor while the list is fetched, the fetched list is
+	// stale and must not be cached.
+	<-listing
+	var out uptrace.MonitorResponse
+	if err := cache.UpdateMonitor(ctx, "1", "1", uptrace.Monitor{Name: "renamed", Type: "error"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	<-done
+
+	if got := readName(t, cache, "1"); got != "renamed" {
+		t.Errorf("read %q after update, want %q", got, "renamed")
+	}
+}
+
+func TestCachingClientMissing(t *testing.T) {
+	ctx := context.Background()
+	cache, api := newTestCache(t, 1)
+	readName(t, cache, "1")
+
+	// Created behind the cache's back, the monitor is missing from the
+	// cached list and read directly.
+	var out uptrace.MonitorResponse
+	if err := api.CreateMonitor(ctx, "1", uptrace.Monitor{Name: "monitor 2", Type: "error"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if got := readName(t, cache, "2"); got != "monitor 2" {
+		t.Errorf("read %q, want %q", got, "monitor 2")
+	}
+	if lists, gets := api.counts(); lists != 1 || gets != 1 {
+		t.Errorf("made %d listings and %d reads, want 1 and 1", lists, gets)
+	}
+}
+
+func TestCachingClientCanceled(t *testing.T) {
+	cache, api := newTestCache(t, 1)
+
+	var once sync.Once
+	listing, release := make(chan struct{}), make(chan struct{})
+	api.onList = func(ctx context.Context) {
+		once.Do(func() {
+			close(listing)
+			<-release
+			if err := ctx.Err(); err != nil {
+				t.Errorf("listing context is done: %s", err)
+			}
+		})
+	}
+
+	// The first reader gives up, the list it started is still fetched and
+	// cached for the others.
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- cache.GetMonitorById(ctx, "1", "1", &uptrace.MonitorResponse{}) }()
+	<-listing
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled read = %v, want %v", err, context.Canceled)
+	}
+	close(release)
+
+	if got := readName(t, cache, "1"); got != "monitor 1" {
+		t.Errorf("read %q, want %q", got, "monitor 1")
+	}
+	if lists, _ := api.counts(); lists != 1 {
+		t.Errorf("made %d listings, want 1", lists)
+	}
+}
@@ -0,0 +1,81 @@
+package uptrace
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DSN holds the connection details encoded in an Uptrace DSN.
+type DSN struct {
+	// Endpoint is the normalized API base URL, see NormalizeEndpoint.
+	Endpoint string
+	Token    string
+	// ProjectID is empty when the DSN does not include the project, as in
+	// the DSNs Uptrace issues for its OpenTelemetry exporters.
+	ProjectID string
+}
+
+// hostedDSNHosts are the hosts used in DSNs issued by the hosted Uptrace
+// cloud, whose API is served from BaseURL instead.
+var hostedDSNHosts = map[string]bool{
+	"uptrace.dev":     true,
+	"api.uptrace.dev": true,
+}
+
+// ParseDSN splits an Uptrace DSN of the form
+// "https://<token>@uptrace.example.com[/prefix][/<project_id>]?grpc=4317"
+// into its parts. A numeric last path segment is taken as the project ID, any
+// other path as the prefix of the API. Query parameters such as grpc only
+// matter to the OpenTelemetry exporters and are ignored.
+func ParseDSN(raw string) (DSN, error) {
+	var dsn DSN
+
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return dsn, fmt.Errorf("DSN must not be empty")
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return dsn, fmt.Errorf("parsing DSN: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return dsn, fmt.Errorf("DSN scheme must be http or https, got %q", parsed.Scheme)
+	}
+	if parsed.Host == "" {
+		return dsn, fmt.Errorf("DSN is missing the host, eg. https://<token>@uptrace.example.com/<project_id>")
+	}
+	if parsed.User == nil || parsed.User.Username() == "" {
+		return dsn, fmt.Errorf("DSN is missing the token before the host, eg. https://<token>@%s", parsed.Host)
+	}
+	if _, ok := parsed.User.Password(); ok {
+		return dsn, fmt.Errorf("DSN user info must only contain the token, found a password after %q", parsed.User.Username())
+	}
+	dsn.Token = parsed.User.Username()
+
+	path := strings.TrimRight(parsed.Path, "/")
+	if idx := strings.LastIndex(path, "/"); idx >= 0 {
+		if _, err := strconv.ParseUint(path[idx+1:], 10, 32); err == nil {
+			dsn.ProjectID = path[idx+1:]
+			path = path[:idx]
+		}
+	}
+
+	if hostedDSNHosts[parsed.Hostname()] {
+		dsn.Endpoint = BaseURL
+		return dsn, nil
+	}
+
+	endpoint := url.URL{
+		Scheme: parsed.Scheme,
+		Host:   parsed.Host,
+		Path:   path,
+	}
+	dsn.Endpoint, err = NormalizeEndpoint(endpoint.String())
+	if err != nil {
+		return dsn, err
+	}
+	return dsn, nil
+}
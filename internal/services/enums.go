@@ -0,0 +1,32 @@
+package uptrace
+
+// The values Uptrace accepts for the enumerated fields of a monitor.
+var (
+	MonitorTypes = []string{"metric", "error"}
+
+	// RepeatIntervalStrategies are the values of RepeatInterval.Strategy
+	// the provider supports. "default" is the adaptive interval. Uptrace
+	// also has "custom", which needs an interval the provider cannot set.
+	RepeatIntervalStrategies = []string{"default"}
+
+	NullsModes    = []string{"allow", "forbid", "convert"}
+	Tolerances    = []string{"low", "medium", "high"}
+	BoundsSources = []string{"manual", "auto"}
+
+	// ColumnUnits are the units Uptrace formats values with. "1" is a
+	// dimensionless number.
+	ColumnUnits = []string{
+		"1",
+		"percents",
+		"utilization",
+		"nanoseconds",
+		"microseconds",
+		"milliseconds",
+		"seconds",
+		"bytes",
+		"kilobytes",
+		"megabytes",
+		"gigabytes",
+		"terabytes",
+	}
+)
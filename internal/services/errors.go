@@ -0,0 +1,121 @@
+package uptrace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrReadOnly is returned by methods that would change data in Uptrace when
+// the client is read-only.
+var ErrReadOnly = errors.New("the Uptrace client is read-only")
+
+// APIError is returned by the client for every non-2xx response.
+type APIError struct {
+	StatusCode int
+	Status     string
+
+	// Code and Message are parsed from the JSON error body, when present.
+	Code    string
+	Message string
+
+	// Fields holds validation errors for individual request fields.
+	Fields []FieldError
+
+	// Body is the raw response body, kept for errors Uptrace does not
+	// report as JSON, eg. from a reverse proxy.
+	Body string
+}
+
+// FieldError is a validation error for a single field of the request body.
+// Field is the JSON path of the field, eg. "params.minAllowedValue" or
+// "params.metrics[0].alias".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type apiErrorBody struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Error   string       `json:"error"`
+	Errors  []FieldError `json:"errors"`
+}
+
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       string(body),
+	}
+
+	var parsed apiErrorBody
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		apiErr.Code = parsed.Code
+		apiErr.Message = parsed.Message
+		if apiErr.Message == "" {
+			apiErr.Message = parsed.Error
+		}
+		apiErr.Fields = parsed.Errors
+	}
+
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "unexpected status %s", e.Status)
+
+	switch {
+	case e.Message != "":
+		fmt.Fprintf(&b, ": %s", e.Message)
+	case len(e.Fields) == 0 && e.Body != "":
+		fmt.Fprintf(&b, ": %s", e.Body)
+	}
+	if e.Code != "" {
+		fmt.Fprintf(&b, " (code %s)", e.Code)
+	}
+	for _, f := range e.Fields {
+		fmt.Fprintf(&b, "\n  %s: %s", f.Field, f.Message)
+	}
+
+	return b.String()
+}
+
+// AsAPIError returns the APIError wrapped in err, if any.
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+func hasStatus(err error, statusCode int) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && apiErr.StatusCode == statusCode
+}
+
+// IsNotFound reports whether err is an API error for a missing object.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is an API error for a conflicting change.
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsUnauthorized reports whether err is an API error for a missing or
+// invalid API key.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether err is an API error for an API key lacking
+// access to the requested object.
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
@@ -0,0 +1,87 @@
+package uptrace_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	uptrace "github.com/persona-ae/terraform-provider-uptrace/internal/services"
+)
+
+// getWithResponse reads a monitor from a server answering with status and
+// body, and returns the error of the client.
+func getWithResponse(t *testing.T, status int, body string) error {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	client := uptrace.NewUptraceClient(srv.URL, "1", "key")
+	client.MaxRetries = 0
+	return client.GetMonitorById(context.Background(), "1", "1", &uptrace.MonitorResponse{})
+}
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   uptrace.APIError
+		text   string
+	}{
+		{
+			status: http.StatusBadRequest,
+			body: `{"code": "invalid", "message": "monitor is invalid", "errors": [
+				{"field": "params.metrics[1].alias", "message": "must be unique"}
+			]}`,
+			want: uptrace.APIError{
+				Code:    "invalid",
+				Message: "monitor is invalid",
+				Fields:  []uptrace.FieldError{{Field: "params.metrics[1].alias", Message: "must be unique"}},
+			},
+			text: "400 Bad Request: monitor is invalid (code invalid)\n  params.metrics[1].alias: must be unique",
+		},
+		// older versions report the message as error
+		{
+			status: http.StatusConflict,
+			body:   `{"error": "monitor was changed"}`,
+			want:   uptrace.APIError{Message: "monitor was changed"},
+			text:   "409 Conflict: monitor was changed",
+		},
+		// not JSON, eg. from a reverse proxy
+		{
+			status: http.StatusInternalServerError,
+			body:   "<html>internal error</html>",
+			text:   "500 Internal Server Error: <html>internal error</html>",
+		},
+		{
+			status: http.StatusNotFound,
+			text:   "unexpected status 404 Not Found",
+		},
+	}
+	for _, test := range tests {
+		err := getWithResponse(t, test.status, test.body)
+		apiErr, ok := uptrace.AsAPIError(err)
+		if !ok {
+			t.Errorf("%d: got %v, want an APIError", test.status, err)
+			continue
+		}
+		if apiErr.StatusCode != test.status || apiErr.Body != test.body || apiErr.Code != test.want.Code ||
+			apiErr.Message != test.want.Message || !slices.Equal(apiErr.Fields, test.want.Fields) {
+			t.Errorf("%d: got %+v, want %+v", test.status, *apiErr, test.want)
+		}
+		if !strings.HasSuffix(err.Error(), test.text) {
+			t.Errorf("%d: got error %q, want it to end in %q", test.status, err, test.text)
+		}
+		if got := uptrace.IsNotFound(err); got != (test.status == http.StatusNotFound) {
+			t.Errorf("%d: IsNotFound = %t", test.status, got)
+		}
+		if got := uptrace.IsConflict(err); got != (test.status == http.StatusConflict) {
+			t.Errorf("%d: IsConflict = %t", test.status, got)
+		}
+	}
+}
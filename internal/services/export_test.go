@@ -0,0 +1,16 @@
+package uptrace
+
+import (
+	"net/http"
+	"time"
+)
+
+// Exported for the tests of package uptrace_test.
+var (
+	ShouldRetry     = shouldRetry
+	ParseRetryAfter = parseRetryAfter
+)
+
+func (u *UptraceClient) Backoff(attempt int, resp *http.Response) time.Duration {
+	return u.backoff(attempt, resp)
+}
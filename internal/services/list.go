@@ -0,0 +1,95 @@
+package uptrace
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"strconv"
+)
+
+// DefaultPageSize is the number of monitors requested per page by
+// ListMonitors.
+const DefaultPageSize = 100
+
+// ListMonitorsOptions selects a page of monitors and filters them on the
+// server. Zero values are not sent.
+type ListMonitorsOptions struct {
+	// Query matches monitor names.
+	Query string
+	// Type selects monitors of a type, eg. "metric" or "error".
+	Type string
+	// State selects monitors with a status, eg. "active" or "paused".
+	State string
+
+	// Limit is the page size, Offset the number of monitors skipped.
+	Limit  int
+	Offset int
+}
+
+// values returns the options as URL query parameters.
+func (o ListMonitorsOptions) values() url.Values {
+	values := url.Values{}
+	if o.Query != "" {
+		values.Set("q", o.Query)
+	}
+	if o.Type != "" {
+		values.Set("type", o.Type)
+	}
+	if o.State != "" {
+		values.Set("state", o.State)
+	}
+	if o.Limit > 0 {
+		values.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		values.Set("offset", strconv.Itoa(o.Offset))
+	}
+	return values
+}
+
+// ListMonitors iterates over all monitors of the project matching the filters
+// of opts, requesting them page by page. opts.Limit sets the page size,
+// DefaultPageSize when zero, and opts.Offset the first monitor. Iteration
+// stops at the first error, which is yielded with a zero Monitor.
+//
+// Paging ends once Count monitors were read, or at the first short page when
+// the server omits Count. A page with no monitor not already yielded also
+// ends it, so that servers ignoring the limit and offset are read once.
+func ListMonitors(ctx context.Context, api MonitorAPI, projectID string, opts ListMonitorsOptions) iter.Seq2[Monitor, error] {
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultPageSize
+	}
+	return func(yield func(Monitor, error) bool) {
+		seen := make(map[int32]bool)
+		for {
+			var page GetMonitorsResponse
+			if err := api.GetMonitors(ctx, projectID, opts, &page); err != nil {
+				yield(Monitor{}, err)
+				return
+			}
+			found := false
+			for _, monitor := range page.Monitors {
+				if seen[monitor.ID] {
+					continue
+				}
+				seen[monitor.ID] = true
+				found = true
+				if !yield(monitor, nil) {
+					return
+				}
+			}
+
+			opts.Offset += len(page.Monitors)
+			switch {
+			case !found:
+				return
+			case page.Count > 0:
+				if opts.Offset >= page.Count {
+					return
+				}
+			case len(page.Monitors) < opts.Limit:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package uptrace_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/persona-ae/terraform-provider-uptrace/internal/fake"
+	uptrace "github.com/persona-ae/terraform-provider-uptrace/internal/services"
+)
+
+// countingAPI counts the listings and reads made through it. When set,
+// onList is called before each listing, eg. to hold it.
+type countingAPI struct {
+	uptrace.MonitorAPI
+	onList func(ctx context.Context)
+
+	mu          sync.Mutex
+	lists, gets int
+}
+
+func (c *countingAPI) GetMonitors(ctx context.Context, projectID string, opts uptrace.ListMonitorsOptions, out *uptrace.GetMonitorsResponse) error {
+	c.mu.Lock()
+	c.lists++
+	c.mu.Unlock()
+	if c.onList != nil {
+		c.onList(ctx)
+	}
+	return c.MonitorAPI.GetMonitors(ctx, projectID, opts, out)
+}
+
+func (c *countingAPI) GetMonitorById(ctx context.Context, projectID, id string, out *uptrace.MonitorResponse) error {
+	c.mu.Lock()
+	c.gets++
+	c.mu.Unlock()
+	return c.MonitorAPI.GetMonitorById(ctx, projectID, id, out)
+}
+
+// counts returns the number of listings and reads so far.
+func (c *countingAPI) counts() (lists, gets int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lists, c.gets
+}
+
+// newTestServer starts a fake server holding n error monitors in project 1.
+func newTestServer(t *testing.T, n int) (*fake.Server, *uptrace.UptraceClient) {
+	t.Helper()
+	srv := fake.NewServer("key", "1")
+	t.Cleanup(srv.Close)
+	for i := range n {
+		monitor := uptrace.Monitor{Name: fmt.Sprintf("monitor %d", i+1), Type: "error"}
+		if err := srv.Backend.CreateMonitor(context.Background(), "1", monitor, &uptrace.MonitorResponse{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return srv, uptrace.NewUptraceClient(srv.URL, "1", "key")
+}
+
+func TestListMonitors(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(*fake.Client)
+		limit     int
+		wantLists int
+	}{
+		{name: "paged", limit: 2, wantLists: 3},
+		{name: "exact pages", limit: 5, wantLists: 1},
+		{name: "capped", setup: func(c *fake.Client) { c.MaxPageSize = 2 }, limit: 4, wantLists: 3},
+		{name: "no paging", setup: func(c *fake.Client) { c.IgnorePaging = true }, limit: 2, wantLists: 2},
+		{name: "no paging short", setup: func(c *fake.Client) { c.IgnorePaging = true }, limit: 10, wantLists: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv, client := newTestServer(t, 5)
+			if test.setup != nil {
+				test.setup(srv.Backend)
+			}
+			api := &countingAPI{MonitorAPI: client}
+
+			var names []string
+			for monitor, err := range uptrace.ListMonitors(context.Background(), api, "1", uptrace.ListMonitorsOptions{Limit: test.limit}) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				names = append(names, monitor.Name)
+			}
+
+			want := []string{"monitor 1", "monitor 2", "monitor 3", "monitor 4", "monitor 5"}
+			if !slices.Equal(names, want) {
+				t.Errorf("listed %q, want %q", names, want)
+			}
+			if api.lists != test.wantLists {
+				t.Errorf("listed in %d requests, want %d", api.lists, test.wantLists)
+			}
+		})
+	}
+}
+
+func TestListMonitorsStop(t *testing.T) {
+	_, client := newTestServer(t, 5)
+	api := &countingAPI{MonitorAPI: client}
+
+	var names []string
+	for monitor, err := range uptrace.ListMonitors(context.Background(), api, "1", uptrace.ListMonitorsOptions{Limit: 2}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, monitor.Name)
+		if len(names) == 3 {
+			break
+		}
+	}
+	if len(names) != 3 || api.lists != 2 {
+		t.Errorf("listed %q in %d requests, want 3 monitors in 2 requests", names, api.lists)
+	}
+}
+
+// roundTripFunc is an http.RoundTripper calling itself.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestListMonitorsFilters(t *testing.T) {
+	srv, client := newTestServer(t, 0)
+	maxAllowedValue := 100.0
+	for _, monitor := range []uptrace.Monitor{
+		{Name: "API latency", Type: "metric", Params: uptrace.Params{
+			Query:           "perMin(sum($spans)) as spans",
+			Metrics:         []uptrace.Metric{{Name: "uptrace_tracing_spans", Alias: "spans"}},
+			MaxAllowedValue: &maxAllowedValue,
+		}},
+		{Name: "API errors", Type: "error"},
+		{Name: "DB errors", Type: "error", Status: "paused"},
+	} {
+		if err := srv.Backend.CreateMonitor(context.Background(), "1", monitor, &uptrace.MonitorResponse{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var query string
+	client.Client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		query = req.URL.RawQuery
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	tests := []struct {
+		opts      uptrace.ListMonitorsOptions
+		wantQuery string
+		want      []string
+	}{
+		{uptrace.ListMonitorsOptions{Query: "api"}, "limit=100&q=api", []string{"API latency", "API errors"}},
+		{uptrace.ListMonitorsOptions{Type: "error"}, "limit=100&type=error", []string{"API errors", "DB errors"}},
+		{uptrace.ListMonitorsOptions{State: "paused"}, "limit=100&state=paused", []string{"DB errors"}},
+		{uptrace.ListMonitorsOptions{Query: "errors", State: "active"}, "limit=100&q=errors&state=active", []string{"API errors"}},
+	}
+	for _, test := range tests {
+		var names []string
+		for monitor, err := range uptrace.ListMonitors(context.Background(), client, "1", test.opts) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, monitor.Name)
+		}
+		if query != test.wantQuery || !slices.Equal(names, test.want) {
+			t.Errorf("ListMonitors(%+v) requested %q and listed %q, want %q and %q", test.opts, query, names, test.wantQuery, test.want)
+		}
+	}
+}
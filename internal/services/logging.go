@@ -0,0 +1,61 @@
+package uptrace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// LogSubsystem is the tflog subsystem used for HTTP request logging. Its
+	// level is controlled with the TF_LOG_PROVIDER_UPTRACE_HTTP environment
+	// variable.
+	LogSubsystem = "uptrace_http"
+
+	logLevelEnv = "TF_LOG_PROVIDER_UPTRACE_HTTP"
+)
+
+// sensitiveBodyFieldRe matches JSON string fields in request and response
+// bodies whose values must not end up in logs.
+var sensitiveBodyFieldRe = regexp.MustCompile(`(?i)"[a-z_]*(token|secret|password|apikey|api_key|dsn)[a-z_]*"\s*:\s*"(?:[^"\\]|\\.)*"`)
+
+// bearerRe matches credentials that slipped into a logged value.
+var bearerRe = regexp.MustCompile(`(?i)bearer\s+[^\s"]+`)
+
+// logContext returns ctx with the HTTP logging subsystem set up.
+func logContext(ctx context.Context) context.Context {
+	ctx = tflog.NewSubsystem(ctx, LogSubsystem, tflog.WithLevelFromEnv(logLevelEnv))
+	ctx = tflog.SubsystemMaskAllFieldValuesRegexes(ctx, LogSubsystem, sensitiveBodyFieldRe, bearerRe)
+	ctx = tflog.SubsystemMaskMessageRegexes(ctx, LogSubsystem, bearerRe)
+	return ctx
+}
+
+// redactHeaders returns the headers in a form suitable for logging, with
+// credentials replaced.
+func redactHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		value := strings.Join(values, ", ")
+		switch http.CanonicalHeaderKey(name) {
+		case "Authorization", "Cookie", "Set-Cookie":
+			value = "***"
+		}
+		headers[name] = value
+	}
+	return headers
+}
+
+// newRequestID returns a random identifier correlating the log lines of a
+// request and its retries.
+func newRequestID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,60 @@
+package uptrace_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	uptrace "github.com/persona-ae/terraform-provider-uptrace/internal/services"
+)
+
+// TestLogRedaction checks that credentials sent to or received from Uptrace
+// never reach the HTTP request logs.
+func TestLogRedaction(t *testing.T) {
+	t.Setenv("TF_LOG_PROVIDER_UPTRACE_HTTP", "DEBUG")
+
+	tests := []struct {
+		name   string
+		header http.Header
+		body   string
+		secret string
+	}{
+		{name: "api key", secret: "s3cret-api-key"},
+		{name: "token", body: `{"token": "t0ken"}`, secret: "t0ken"},
+		{name: "escaped quotes", body: `{"token": "a\"t0ken-tail"}`, secret: "t0ken-tail"},
+		{name: "api key field", body: `{"project": {"apiKey": "k3y"}}`, secret: "k3y"},
+		{name: "dsn", body: `{"projectDsn": "https://dsn-t0ken@uptrace.example.com/1"}`, secret: "dsn-t0ken"},
+		{name: "password", body: `{"password": "p4ss"}`, secret: "p4ss"},
+		{name: "bearer in a message", body: `{"message": "rejected Bearer b34rer"}`, secret: "b34rer"},
+		{name: "authorization", header: http.Header{"Authorization": {"Bearer 3choed"}}, secret: "3choed"},
+		{name: "cookie", header: http.Header{"Set-Cookie": {"session=c00kie"}}, secret: "c00kie"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for name, values := range test.header {
+					w.Header()[name] = values
+				}
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(test.body))
+			}))
+			defer srv.Close()
+
+			var logs bytes.Buffer
+			ctx := tflogtest.RootLogger(context.Background(), &logs)
+			client := uptrace.NewUptraceClient(srv.URL, "1", "s3cret-api-key")
+			_ = client.GetMonitorById(ctx, "1", "1", &uptrace.MonitorResponse{})
+
+			if !strings.Contains(logs.String(), "Received Uptrace response") {
+				t.Fatalf("the response was not logged:\n%s", logs.String())
+			}
+			if strings.Contains(logs.String(), test.secret) {
+				t.Errorf("the logs include %q:\n%s", test.secret, logs.String())
+			}
+		})
+	}
+}
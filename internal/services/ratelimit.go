@@ -0,0 +1,46 @@
+package uptrace
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/time/rate"
+)
+
+// SetRateLimit limits the client to requestsPerSecond requests per second
+// and at most maxConcurrent requests in flight. A value of zero disables the
+// respective limit. The limits apply to every resource sharing the client,
+// and every retry counts as a separate request.
+func (u *UptraceClient) SetRateLimit(requestsPerSecond float64, maxConcurrent int) {
+	u.limiter = nil
+	if requestsPerSecond > 0 {
+		// Allow a burst of one second worth of requests, but at least one.
+		burst := max(int(requestsPerSecond), 1)
+		u.limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+	}
+
+	u.inFlight = nil
+	if maxConcurrent > 0 {
+		u.inFlight = make(chan struct{}, maxConcurrent)
+	}
+}
+
+// acquire blocks until a request may be sent according to the configured
+// limits. The returned function must be called once the request is done.
+func (u *UptraceClient) acquire(ctx context.Context) (func(), error) {
+	if u.limiter != nil {
+		if err := u.limiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("waiting for rate limiter: %w", err)
+		}
+	}
+
+	if u.inFlight == nil {
+		return func() {}, nil
+	}
+	select {
+	case u.inFlight <- struct{}{}:
+		return func() { <-u.inFlight }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for a free request slot: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,110 @@
+package uptrace_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	uptrace "github.com/persona-ae/terraform-provider-uptrace/internal/services"
+)
+
+// concurrencyServer counts the requests it is serving at once, each taking
+// delay.
+type concurrencyServer struct {
+	*httptest.Server
+	delay time.Duration
+
+	mu                 sync.Mutex
+	inFlight, requests int
+	maxInFlight        int
+}
+
+func newConcurrencyServer(t *testing.T, delay time.Duration) *concurrencyServer {
+	t.Helper()
+	s := &concurrencyServer{delay: delay}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests++
+		s.inFlight++
+		s.maxInFlight = max(s.maxInFlight, s.inFlight)
+		s.mu.Unlock()
+
+		time.Sleep(s.delay)
+
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+		_, _ = w.Write([]byte(`{"monitor": {"id": 1}}`))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// counts returns the number of requests so far and the most served at once.
+func (s *concurrencyServer) counts() (requests, maxInFlight int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests, s.maxInFlight
+}
+
+// getAll reads a monitor n times in parallel.
+func getAll(t *testing.T, client *uptrace.UptraceClient, n int) {
+	t.Helper()
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := client.GetMonitorById(context.Background(), "1", "1", &uptrace.MonitorResponse{}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSetRateLimitConcurrency(t *testing.T) {
+	srv := newConcurrencyServer(t, 20*time.Millisecond)
+	client := uptrace.NewUptraceClient(srv.URL, "1", "key")
+	client.SetRateLimit(0, 2)
+
+	getAll(t, client, 10)
+	if requests, maxInFlight := srv.counts(); requests != 10 || maxInFlight != 2 {
+		t.Errorf("got %d requests with at most %d in flight, want 10 with 2", requests, maxInFlight)
+	}
+}
+
+func TestSetRateLimitRequestsPerSecond(t *testing.T) {
+	srv := newConcurrencyServer(t, 0)
+	client := uptrace.NewUptraceClient(srv.URL, "1", "key")
+	client.SetRateLimit(20, 0)
+
+	// The burst of 20 requests passes at once, the next 10 take half a
+	// second.
+	start := time.Now()
+	getAll(t, client, 30)
+	elapsed := time.Since(start)
+	if requests, _ := srv.counts(); requests != 30 || elapsed < 400*time.Millisecond {
+		t.Errorf("got %d requests in %s, want 30 in at least 400ms", requests, elapsed)
+	}
+}
+
+func TestSetRateLimitCanceled(t *testing.T) {
+	srv := newConcurrencyServer(t, 0)
+	client := uptrace.NewUptraceClient(srv.URL, "1", "key")
+	client.SetRateLimit(0.1, 0)
+
+	// The first request uses the burst, the second would wait ten seconds.
+	if err := client.GetMonitorById(context.Background(), "1", "1", &uptrace.MonitorResponse{}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := client.GetMonitorById(ctx, "1", "1", &uptrace.MonitorResponse{})
+	if requests, _ := srv.counts(); err == nil || requests != 1 {
+		t.Errorf("got %v after %d requests, want an error after 1", err, requests)
+	}
+}
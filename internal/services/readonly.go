@@ -0,0 +1,24 @@
+package uptrace
+
+import "context"
+
+// ReadOnlyClient makes every method of a MonitorAPI that would change data in
+// Uptrace fail with ErrReadOnly, like UptraceClient.ReadOnly does for the
+// HTTP client. Reads are passed through.
+type ReadOnlyClient struct {
+	MonitorAPI
+}
+
+var _ MonitorAPI = ReadOnlyClient{}
+
+func (c ReadOnlyClient) CreateMonitor(ctx context.Context, projectID string, req Monitor, out *MonitorResponse) error {
+	return ErrReadOnly
+}
+
+func (c ReadOnlyClient) UpdateMonitor(ctx context.Context, projectID, id string, req Monitor, out *MonitorResponse) error {
+	return ErrReadOnly
+}
+
+func (c ReadOnlyClient) DeleteMonitor(ctx context.Context, projectID, id string) error {
+	return ErrReadOnly
+}
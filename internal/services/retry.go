@@ -0,0 +1,120 @@
+package uptrace
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultMaxRetries   = 4
+	DefaultRetryWaitMin = 1 * time.Second
+	DefaultRetryWaitMax = 30 * time.Second
+
+	// MaxRetryAfter caps the wait a Retry-After header can ask for, so that a
+	// misbehaving server cannot stall an apply indefinitely.
+	MaxRetryAfter = 5 * time.Minute
+)
+
+// shouldRetry reports whether a request may be sent again after it failed
+// with resp or err. Idempotent methods are retried on connection errors,
+// rate limiting and gateway errors. POST is only retried when the server
+// rejected it with 429 Too Many Requests, because it was not processed and
+// retrying cannot create a duplicate monitor.
+func shouldRetry(ctx context.Context, method string, resp *http.Response, err error) bool {
+	// A timed out attempt is retried, but not once the operation itself was
+	// cancelled.
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+	default:
+		return false
+	}
+
+	if err != nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff returns how long to wait before retrying after the given attempt,
+// starting at zero. A Retry-After header on resp takes precedence, up to
+// MaxRetryAfter, otherwise
+// the wait grows exponentially from RetryWaitMin up to RetryWaitMax with
+// jitter so that parallel requests do not retry in lockstep.
+func (u *UptraceClient) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return min(wait, MaxRetryAfter)
+		}
+	}
+
+	wait := u.RetryWaitMin
+	for i := 0; i < attempt && wait < u.RetryWaitMax; i++ {
+		wait *= 2
+	}
+	if wait > u.RetryWaitMax {
+		wait = u.RetryWaitMax
+	}
+	if wait <= 0 {
+		return 0
+	}
+
+	// Jitter within the upper half of the interval.
+	half := wait / 2
+	return half + rand.N(half+1)
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as
+// an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		wait := time.Until(at)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// exceedsDeadline reports whether waiting for d would outlast the deadline of
+// ctx, in which case the retry is bound to fail.
+func exceedsDeadline(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && time.Until(deadline) < d
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
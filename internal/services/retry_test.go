@@ -0,0 +1,188 @@
+package uptrace_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	uptrace "github.com/persona-ae/terraform-provider-uptrace/internal/services"
+)
+
+func TestShouldRetry(t *testing.T) {
+	errConn := errors.New("connection reset")
+	tests := []struct {
+		method string
+		status int // zero for a connection error
+		want   bool
+	}{
+		{http.MethodGet, 0, true},
+		{http.MethodGet, http.StatusTooManyRequests, true},
+		{http.MethodGet, http.StatusBadGateway, true},
+		{http.MethodGet, http.StatusServiceUnavailable, true},
+		{http.MethodGet, http.StatusGatewayTimeout, true},
+		{http.MethodGet, http.StatusInternalServerError, false},
+		{http.MethodGet, http.StatusNotFound, false},
+		{http.MethodGet, http.StatusOK, false},
+		{http.MethodPut, http.StatusBadGateway, true},
+		{http.MethodDelete, 0, true},
+		// POST is only retried when it was not processed
+		{http.MethodPost, http.StatusTooManyRequests, true},
+		{http.MethodPost, 0, false},
+		{http.MethodPost, http.StatusBadGateway, false},
+		{http.MethodPost, http.StatusServiceUnavailable, false},
+	}
+	for _, test := range tests {
+		var (
+			resp *http.Response
+			err  error
+		)
+		if test.status == 0 {
+			err = errConn
+		} else {
+			resp = &http.Response{StatusCode: test.status}
+		}
+		if got := uptrace.ShouldRetry(context.Background(), test.method, resp, err); got != test.want {
+			t.Errorf("ShouldRetry(%s, %d) = %t, want %t", test.method, test.status, got, test.want)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if uptrace.ShouldRetry(ctx, http.MethodGet, &http.Response{StatusCode: http.StatusBadGateway}, nil) {
+		t.Error("ShouldRetry retried a canceled request")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	client := uptrace.NewUptraceClient("", "1", "key")
+	client.RetryWaitMin = time.Second
+	client.RetryWaitMax = 10 * time.Second
+
+	// The wait is jittered within the upper half of the interval.
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{3, 8 * time.Second},
+		{4, 10 * time.Second},
+		{30, 10 * time.Second},
+	}
+	for _, test := range tests {
+		for range 20 {
+			got := client.Backoff(test.attempt, nil)
+			if got < test.max/2 || got > test.max {
+				t.Errorf("Backoff(%d) = %s, want between %s and %s", test.attempt, got, test.max/2, test.max)
+			}
+		}
+	}
+
+	retryAfter := func(value string) *http.Response {
+		return &http.Response{Header: http.Header{"Retry-After": {value}}}
+	}
+	if got := client.Backoff(0, retryAfter("20")); got != 20*time.Second {
+		t.Errorf("Backoff with Retry-After 20 = %s, want 20s", got)
+	}
+	if got := client.Backoff(0, retryAfter("86400")); got != uptrace.MaxRetryAfter {
+		t.Errorf("Backoff with Retry-After 86400 = %s, want %s", got, uptrace.MaxRetryAfter)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value    string
+		min, max time.Duration
+		ok       bool
+	}{
+		{"", 0, 0, false},
+		{"0", 0, 0, true},
+		{"5", 5 * time.Second, 5 * time.Second, true},
+		{"-1", 0, 0, false},
+		{"soon", 0, 0, false},
+		{time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), 58 * time.Second, time.Minute, true},
+		{time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), 0, 0, true},
+	}
+	for _, test := range tests {
+		got, ok := uptrace.ParseRetryAfter(test.value)
+		if ok != test.ok || got < test.min || got > test.max {
+			t.Errorf("ParseRetryAfter(%q) = %s, %t, want between %s and %s, %t", test.value, got, ok, test.min, test.max, test.ok)
+		}
+	}
+}
+
+// newFailingServer starts a server answering the first requests with the
+// given statuses and every later one with a monitor. It returns a client for
+// it with short retry waits and the number of requests received.
+func newFailingServer(t *testing.T, statuses ...int) (*uptrace.UptraceClient, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n := int(requests.Add(1)); n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"monitor": {"id": 1, "name": "spans"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	client := uptrace.NewUptraceClient(srv.URL, "1", "key")
+	client.RetryWaitMin = time.Millisecond
+	client.RetryWaitMax = 10 * time.Millisecond
+	return client, &requests
+}
+
+func TestUptraceClientRetry(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusBadGateway} {
+		t.Run(strconv.Itoa(status), func(t *testing.T) {
+			client, requests := newFailingServer(t, status)
+
+			var out uptrace.MonitorResponse
+			if err := client.GetMonitorById(context.Background(), "1", "1", &out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Monitor.Name != "spans" || requests.Load() != 2 {
+				t.Errorf("got monitor %q after %d requests, want spans after 2", out.Monitor.Name, requests.Load())
+			}
+		})
+	}
+
+	t.Run("post", func(t *testing.T) {
+		client, requests := newFailingServer(t, http.StatusBadGateway)
+
+		err := client.CreateMonitor(context.Background(), "1", uptrace.Monitor{}, &uptrace.MonitorResponse{})
+		if err == nil || requests.Load() != 1 {
+			t.Errorf("CreateMonitor = %v after %d requests, want an error after 1", err, requests.Load())
+		}
+	})
+
+	t.Run("no retries", func(t *testing.T) {
+		client, requests := newFailingServer(t, http.StatusServiceUnavailable)
+		client.MaxRetries = 0
+
+		err := client.GetMonitorById(context.Background(), "1", "1", &uptrace.MonitorResponse{})
+		if err == nil || requests.Load() != 1 {
+			t.Errorf("GetMonitorById = %v after %d requests, want an error after 1", err, requests.Load())
+		}
+	})
+
+	t.Run("past deadline", func(t *testing.T) {
+		client, requests := newFailingServer(t, http.StatusServiceUnavailable)
+		client.RetryWaitMin = time.Minute
+		client.RetryWaitMax = time.Minute
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		err := client.GetMonitorById(ctx, "1", "1", &uptrace.MonitorResponse{})
+		apiErr, ok := uptrace.AsAPIError(err)
+		if !ok || apiErr.StatusCode != http.StatusServiceUnavailable || requests.Load() != 1 {
+			t.Errorf("GetMonitorById = %v after %d requests, want 503 after 1", err, requests.Load())
+		}
+	})
+}
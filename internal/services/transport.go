@@ -0,0 +1,93 @@
+package uptrace
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const DefaultRequestTimeout = 1 * time.Minute
+
+// TransportOptions configures the HTTP client used to reach the Uptrace API.
+type TransportOptions struct {
+	// Timeout bounds a single request attempt, including reading the body.
+	Timeout time.Duration
+
+	// CACertPEM holds additional PEM encoded certificate authorities trusted
+	// on top of the system pool.
+	CACertPEM          string
+	InsecureSkipVerify bool
+
+	// ProxyURL overrides the proxy taken from the HTTPS_PROXY and NO_PROXY
+	// environment variables.
+	ProxyURL string
+
+	// ClientCertPEM and ClientKeyPEM hold the PEM encoded certificate and
+	// private key presented for mutual TLS. Both or neither must be set.
+	ClientCertPEM string
+	ClientKeyPEM  string
+}
+
+// NewHTTPClient builds an HTTP client with a custom transport from opts.
+func NewHTTPClient(opts TransportOptions) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: opts.InsecureSkipVerify, //nolint:gosec // explicitly requested by the user
+	}
+
+	if opts.CACertPEM != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(opts.CACertPEM)) {
+			return nil, fmt.Errorf("no valid PEM encoded certificates found in the CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if opts.ClientCertPEM != "" || opts.ClientKeyPEM != "" {
+		if opts.ClientCertPEM == "" || opts.ClientKeyPEM == "" {
+			return nil, fmt.Errorf("both a client certificate and a client key are required for mutual TLS")
+		}
+		cert, err := tls.X509KeyPair([]byte(opts.ClientCertPEM), []byte(opts.ClientKeyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+
+	if opts.ProxyURL != "" {
+		proxy, err := url.Parse(opts.ProxyURL)
+		if err != nil {
+			// The error quotes the URL, which may include a password.
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				err = urlErr.Err
+			}
+			return nil, fmt.Errorf("parsing proxy URL: %w", err)
+		}
+		switch proxy.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("proxy URL scheme must be http, https or socks5, got %q", proxy.Scheme)
+		}
+		if proxy.Host == "" {
+			return nil, fmt.Errorf("proxy URL must include a host")
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   opts.Timeout,
+	}, nil
+}
@@ -7,11 +7,20 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/persona-ae/terraform-provider-uptrace/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
 )
 
 const (
+	// BaseURL is the API endpoint of the hosted Uptrace cloud, used when no
+	// endpoint is configured.
 	BaseURL = "https://api2.uptrace.dev"
 )
 
@@ -20,103 +29,264 @@ type UptraceClient struct {
 	ProjectID string
 	APIKey    string
 	Client    *http.Client
+
+	// UserAgent is sent with every request, see UserAgent.
+	UserAgent string
+
+	// ReadOnly makes every method that would change data in Uptrace fail
+	// with ErrReadOnly without sending a request.
+	ReadOnly bool
+
+	// MaxRetries is the number of times a request failing with a transient
+	// error is retried, waiting between RetryWaitMin and RetryWaitMax.
+	MaxRetries   int
+	RetryWaitMin time.Duration
+	RetryWaitMax time.Duration
+
+	// set by SetRateLimit, nil when unlimited
+	limiter  *rate.Limiter
+	inFlight chan struct{}
 }
 
-func NewUptraceClient(projectID, apiKey string) *UptraceClient {
+// NewUptraceClient returns a client for the Uptrace API at baseURL. An empty
+// baseURL selects the hosted Uptrace cloud. The base URL is expected to be
+// normalized already, see NormalizeEndpoint.
+func NewUptraceClient(baseURL, projectID, apiKey string) *UptraceClient {
+	if baseURL == "" {
+		baseURL = BaseURL
+	}
 	return &UptraceClient{
-		BaseURL:   BaseURL,
+		BaseURL:   baseURL,
 		ProjectID: projectID,
 		APIKey:    apiKey,
 		Client:    &http.Client{},
+
+		MaxRetries:   DefaultMaxRetries,
+		RetryWaitMin: DefaultRetryWaitMin,
+		RetryWaitMax: DefaultRetryWaitMax,
+	}
+}
+
+// NormalizeEndpoint validates an Uptrace API endpoint and returns it in the
+// form expected by NewUptraceClient: an absolute http(s) URL without query,
+// fragment or trailing slash. A path is kept so that installations served
+// under a prefix by a reverse proxy, eg. "https://example.com/uptrace", work.
+func NormalizeEndpoint(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("endpoint must not be empty")
 	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("parsing endpoint: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", fmt.Errorf("endpoint must use the http or https scheme, got %q", parsed.Scheme)
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("endpoint must include a host")
+	}
+	if parsed.User != nil {
+		return "", fmt.Errorf("endpoint must not include credentials")
+	}
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		return "", fmt.Errorf("endpoint must not include a query or fragment")
+	}
+
+	parsed.Path = strings.TrimRight(parsed.Path, "/")
+	parsed.RawPath = ""
+	return parsed.String(), nil
 }
 
-func (u *UptraceClient) do(ctx context.Context, method, endpoint string, in any, out any) error {
+func (u *UptraceClient) do(ctx context.Context, method, endpoint string, in any, out any) (err error) {
 	url := u.BaseURL + endpoint
 
-	var reqBody io.Reader
+	requestID := newRequestID()
+
+	// One span covers all attempts of a request, so that time spent waiting
+	// for retries and the rate limiter shows up in traces.
+	ctx, span := telemetry.Tracer().Start(ctx, "uptrace "+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", method),
+			attribute.String("url.full", url),
+			attribute.String("url.path", endpoint),
+			attribute.String("uptrace.request_id", requestID),
+		),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	ctx = logContext(ctx)
+	ctx = tflog.SubsystemSetField(ctx, LogSubsystem, "request_id", requestID)
+	ctx = tflog.SubsystemSetField(ctx, LogSubsystem, "method", method)
+	ctx = tflog.SubsystemSetField(ctx, LogSubsystem, "url", url)
+
+	var body []byte
 	if in != nil {
-		body, err := json.Marshal(in)
+		var err error
+		body, err = json.Marshal(in)
 		if err != nil {
 			return fmt.Errorf("marshaling request body: %w", err)
 		}
-		reqBody = bytes.NewReader(body)
+	}
 
-		fmt.Println(string(body))
+	var (
+		resp     *http.Response
+		respBody []byte
+	)
+	for attempt := 0; ; attempt++ {
+		var err error
+		resp, respBody, err = u.send(tflog.SubsystemSetField(ctx, LogSubsystem, "attempt", attempt+1), method, url, requestID, body)
+		span.SetAttributes(attribute.Int("http.request.resend_count", attempt))
+		if resp != nil {
+			span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+		}
 
-		//tflog.Debug(ctx, "Uptrace request", map[string]any{
-		fmt.Println("Uptrace request", map[string]any{
-			"method": method,
-			"url":    url,
-			"body":   string(body),
-			"in":     in,
-		})
-	} else {
-		//tflog.Debug(ctx, "Uptrace request (no body)", map[string]any{
-		fmt.Println("Uptrace request (no body)", map[string]any{
-			"method": method,
-			"url":    url,
+		wait := u.backoff(attempt, resp)
+		if attempt >= u.MaxRetries || !shouldRetry(ctx, method, resp, err) || exceedsDeadline(ctx, wait) {
+			if err != nil {
+				return err
+			}
+			break
+		}
+
+		tflog.SubsystemDebug(ctx, LogSubsystem, "Retrying Uptrace request", map[string]any{
+			"attempt": attempt + 1,
+			"wait":    wait.String(),
+			"error":   fmt.Sprint(err),
 		})
+		if err := sleep(ctx, wait); err != nil {
+			return fmt.Errorf("waiting to retry request: %w", err)
+		}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newAPIError(resp, respBody)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("decoding response JSON: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// send performs a single attempt of a request and reads the whole response.
+// The requestID is the same for all attempts of a request.
+func (u *UptraceClient) send(ctx context.Context, method, url, requestID string, body []byte) (*http.Response, []byte, error) {
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
+		return nil, nil, fmt.Errorf("creating request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+u.APIKey)
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(RequestIDHeader, requestID)
+	if u.UserAgent != "" {
+		req.Header.Set("User-Agent", u.UserAgent)
+	}
+
+	release, err := u.acquire(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+
+	tflog.SubsystemDebug(ctx, LogSubsystem, "Sending Uptrace request", map[string]any{
+		"headers": redactHeaders(req.Header),
+		"body":    string(body),
+	})
 
+	start := time.Now()
 	resp, err := u.Client.Do(req)
 	if err != nil {
-		return fmt.Errorf("performing request: %w", err)
+		tflog.SubsystemDebug(ctx, LogSubsystem, "Uptrace request failed", map[string]any{
+			"duration_ms": time.Since(start).Milliseconds(),
+			"error":       err.Error(),
+		})
+		return nil, nil, fmt.Errorf("performing request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("reading response: %w", err)
+		return resp, nil, fmt.Errorf("reading response: %w", err)
 	}
 
-	tflog.Debug(ctx, "Uptrace response", map[string]any{
-		"status": resp.Status,
-		"body":   string(respBody),
+	tflog.SubsystemDebug(ctx, LogSubsystem, "Received Uptrace response", map[string]any{
+		"status":      resp.StatusCode,
+		"duration_ms": time.Since(start).Milliseconds(),
+		"headers":     redactHeaders(resp.Header),
+		"body":        string(respBody),
 	})
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("unexpected status %s: %s", resp.Status, respBody)
-	}
+	return resp, respBody, nil
+}
 
-	if out != nil {
-		if err := json.Unmarshal(respBody, out); err != nil {
-			return fmt.Errorf("decoding response JSON: %w", err)
-		}
-	}
+// DefaultProjectID returns the project used by callers that do not specify
+// one.
+func (u *UptraceClient) DefaultProjectID() string {
+	return u.ProjectID
+}
 
-	return nil
+// CheckAccess verifies that the API key is valid and grants access to the
+// project with a single cheap request.
+func (u *UptraceClient) CheckAccess(ctx context.Context, projectID string) error {
+	return CheckAccess(ctx, u, projectID)
+}
+
+// CheckAccess verifies that api grants access to the project by listing a
+// single monitor.
+func CheckAccess(ctx context.Context, api MonitorAPI, projectID string) error {
+	var out GetMonitorsResponse
+	return api.GetMonitors(ctx, projectID, ListMonitorsOptions{Limit: 1}, &out)
 }
 
-func (u *UptraceClient) GetMonitors(ctx context.Context, out *GetMonitorsResponse) error {
-	endpoint := fmt.Sprintf("/internal/v1/projects/%s/monitors", u.ProjectID)
+// The monitor endpoints take the project explicitly so that a single client
+// can manage monitors in several projects. ProjectID is only the default used
+// by callers that do not specify one.
+
+func (u *UptraceClient) GetMonitors(ctx context.Context, projectID string, opts ListMonitorsOptions, out *GetMonitorsResponse) error {
+	endpoint := fmt.Sprintf("/internal/v1/projects/%s/monitors", projectID)
+	if query := opts.values().Encode(); query != "" {
+		endpoint += "?" + query
+	}
 	return u.do(ctx, "GET", endpoint, nil, out)
 }
 
-func (u *UptraceClient) GetMonitorById(ctx context.Context, id string, out *MonitorResponse) error {
-	endpoint := fmt.Sprintf("/internal/v1/projects/%s/monitors/%s", u.ProjectID, id)
+func (u *UptraceClient) GetMonitorById(ctx context.Context, projectID, id string, out *MonitorResponse) error {
+	endpoint := fmt.Sprintf("/internal/v1/projects/%s/monitors/%s", projectID, id)
 	return u.do(ctx, "GET", endpoint, nil, out)
 }
 
-func (u *UptraceClient) CreateMonitor(ctx context.Context, req Monitor, out *MonitorResponse) error {
-	endpoint := fmt.Sprintf("/internal/v1/projects/%s/monitors", u.ProjectID)
+func (u *UptraceClient) CreateMonitor(ctx context.Context, projectID string, req Monitor, out *MonitorResponse) error {
+	if u.ReadOnly {
+		return ErrReadOnly
+	}
+	endpoint := fmt.Sprintf("/internal/v1/projects/%s/monitors", projectID)
 	return u.do(ctx, "POST", endpoint, req, out)
 }
 
-func (u *UptraceClient) UpdateMonitor(ctx context.Context, id string, req Monitor, out *MonitorResponse) error {
-	endpoint := fmt.Sprintf("/internal/v1/projects/%s/monitors/%s", u.ProjectID, id)
+func (u *UptraceClient) UpdateMonitor(ctx context.Context, projectID, id string, req Monitor, out *MonitorResponse) error {
+	if u.ReadOnly {
+		return ErrReadOnly
+	}
+	endpoint := fmt.Sprintf("/internal/v1/projects/%s/monitors/%s", projectID, id)
 	return u.do(ctx, "PUT", endpoint, req, out)
 }
 
-func (u *UptraceClient) DeleteMonitor(ctx context.Context, id string) error {
-	endpoint := fmt.Sprintf("/internal/v1/projects/%s/monitors/%s", u.ProjectID, id)
+func (u *UptraceClient) DeleteMonitor(ctx context.Context, projectID, id string) error {
+	if u.ReadOnly {
+		return ErrReadOnly
+	}
+	endpoint := fmt.Sprintf("/internal/v1/projects/%s/monitors/%s", projectID, id)
 	return u.do(ctx, "DELETE", endpoint, nil, nil)
 }
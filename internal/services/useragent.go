@@ -0,0 +1,27 @@
+package uptrace
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RequestIDHeader carries an identifier that is unique per request and kept
+// across its retries, so that provider logs can be matched with the access
+// logs of the Uptrace installation.
+const RequestIDHeader = "X-Request-Id"
+
+// UserAgent builds the User-Agent of the provider, eg.
+// "terraform-provider-uptrace/1.2.3 terraform/1.9.0", followed by any extra
+// products given.
+func UserAgent(providerVersion, terraformVersion string, extra ...string) string {
+	parts := []string{fmt.Sprintf("terraform-provider-uptrace/%s", providerVersion)}
+	if terraformVersion != "" {
+		parts = append(parts, fmt.Sprintf("terraform/%s", terraformVersion))
+	}
+	for _, e := range extra {
+		if e = strings.TrimSpace(e); e != "" {
+			parts = append(parts, e)
+		}
+	}
+	return strings.Join(parts, " ")
+}
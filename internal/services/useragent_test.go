@@ -0,0 +1,27 @@
+package uptrace_test
+
+import (
+	"testing"
+
+	uptrace "github.com/persona-ae/terraform-provider-uptrace/internal/services"
+)
+
+func TestUserAgent(t *testing.T) {
+	tests := []struct {
+		providerVersion, terraformVersion string
+		extra                             []string
+		want                              string
+	}{
+		{"1.2.3", "1.9.0", nil, "terraform-provider-uptrace/1.2.3 terraform/1.9.0"},
+		{"dev", "", nil, "terraform-provider-uptrace/dev"},
+		// extra_user_agent and TF_APPEND_USER_AGENT, empty when not set
+		{"1.2.3", "1.9.0", []string{"ci/42", ""}, "terraform-provider-uptrace/1.2.3 terraform/1.9.0 ci/42"},
+		{"1.2.3", "1.9.0", []string{" ci/42 ", "atlantis/0.28 (+https://example.com)"},
+			"terraform-provider-uptrace/1.2.3 terraform/1.9.0 ci/42 atlantis/0.28 (+https://example.com)"},
+	}
+	for _, test := range tests {
+		if got := uptrace.UserAgent(test.providerVersion, test.terraformVersion, test.extra...); got != test.want {
+			t.Errorf("UserAgent(%q, %q, %q) = %q, want %q", test.providerVersion, test.terraformVersion, test.extra, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package uptrace
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// VCR modes selected with the UPTRACE_VCR_MODE environment variable. In
+// record mode every request is sent to Uptrace and stored, sanitized, in the
+// cassette file named by UPTRACE_VCR_CASSETTE. In replay mode requests are
+// answered from the cassette without touching the network.
+const (
+	VCRModeOff    = "off"
+	VCRModeRecord = "record"
+	VCRModeReplay = "replay"
+
+	EnvVCRMode     = "UPTRACE_VCR_MODE"
+	EnvVCRCassette = "UPTRACE_VCR_CASSETTE"
+)
+
+const redacted = "REDACTED"
+
+var projectPathRe = regexp.MustCompile(`/projects/[^/?]+`)
+
+// projectIDFieldRe matches the project id in bodies, recorded as
+// redactedProjectID and replayed as the project of the client.
+var projectIDFieldRe = regexp.MustCompile(`"projectId"\s*:\s*\d+`)
+
+const redactedProjectID = `"projectId":0`
+
+// Cassette is the on-disk format of recorded interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and its response. Requests are stored
+// without scheme, host and project id, so a cassette can be replayed against
+// any endpoint and project.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Body   string `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+}
+
+// cassettes are shared by all clients in the process, because Terraform
+// configures a new provider instance for every command of an acceptance test
+// and replay must continue where the previous instance stopped.
+var (
+	cassettesMu sync.Mutex
+	cassettes   = map[string]*cassetteState{}
+)
+
+type cassetteState struct {
+	mu       sync.Mutex
+	path     string
+	cassette Cassette
+	used     []bool
+}
+
+// VCRModeFromEnv returns the VCR mode and cassette path from the
+// environment, validating them.
+func VCRModeFromEnv() (mode, cassette string, err error) {
+	mode = strings.ToLower(os.Getenv(EnvVCRMode))
+	switch mode {
+	case "", VCRModeOff:
+		return VCRModeOff, "", nil
+	case VCRModeRecord, VCRModeReplay:
+	default:
+		return "", "", fmt.Errorf("%s must be one of %q, %q or %q, got %q", EnvVCRMode, VCRModeRecord, VCRModeReplay, VCRModeOff, mode)
+	}
+
+	cassette = os.Getenv(EnvVCRCassette)
+	if cassette == "" {
+		return "", "", fmt.Errorf("%s is required when %s is %q", EnvVCRCassette, EnvVCRMode, mode)
+	}
+	return mode, cassette, nil
+}
+
+// NewVCRTransport wraps next so that interactions are recorded to or
+// replayed from the cassette at path. The project, in paths and bodies, and
+// the secrets, such as the API key, are scrubbed from everything recorded.
+// Replayed bodies refer to projectID.
+func NewVCRTransport(mode, path string, next http.RoundTripper, projectID string, secrets ...string) (http.RoundTripper, error) {
+	switch mode {
+	case VCRModeOff:
+		return next, nil
+	case VCRModeRecord, VCRModeReplay:
+	default:
+		return nil, fmt.Errorf("unknown VCR mode %q", mode)
+	}
+
+	state, err := openCassette(mode, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vcrTransport{
+		mode:      mode,
+		state:     state,
+		next:      next,
+		projectID: projectID,
+		secrets:   secrets,
+	}, nil
+}
+
+func openCassette(mode, path string) (*cassetteState, error) {
+	cassettesMu.Lock()
+	defer cassettesMu.Unlock()
+
+	if state, ok := cassettes[path]; ok {
+		return state, nil
+	}
+
+	state := &cassetteState{path: path}
+	if mode == VCRModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading cassette: %w", err)
+		}
+		if err := json.Unmarshal(data, &state.cassette); err != nil {
+			return nil, fmt.Errorf("decoding cassette %s: %w", path, err)
+		}
+		state.used = make([]bool, len(state.cassette.Interactions))
+	}
+
+	cassettes[path] = state
+	return state, nil
+}
+
+type vcrTransport struct {
+	mode      string
+	state     *cassetteState
+	next      http.RoundTripper
+	projectID string
+	secrets   []string
+}
+
+func (t *vcrTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	recorded := RecordedRequest{
+		Method: req.Method,
+		Path:   projectPathRe.ReplaceAllString(t.sanitize(req.URL.RequestURI()), "/projects/{project}"),
+		Body:   t.sanitize(string(body)),
+	}
+
+	if t.mode == VCRModeReplay {
+		return t.replay(req, recorded)
+	}
+	return t.record(req, recorded)
+}
+
+func (t *vcrTransport) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	s := t.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, interaction := range s.cassette.Interactions {
+		if s.used[i] || interaction.Request != recorded {
+			continue
+		}
+		s.used[i] = true
+
+		body := interaction.Response.Body
+		if _, err := strconv.ParseUint(t.projectID, 10, 32); err == nil {
+			body = projectIDFieldRe.ReplaceAllString(body, `"projectId":`+t.projectID)
+		}
+		resp := &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{},
+			Body:          io.NopCloser(strings.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}
+		for name, value := range interaction.Response.Headers {
+			resp.Header.Set(name, value)
+		}
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("no unused interaction in cassette %s matches %s %s", s.path, recorded.Method, recorded.Path)
+}
+
+func (t *vcrTransport) record(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Body:       t.sanitize(string(respBody)),
+		},
+	}
+	for _, name := range []string{"Content-Type", "Retry-After"} {
+		if value := resp.Header.Get(name); value != "" {
+			if interaction.Response.Headers == nil {
+				interaction.Response.Headers = map[string]string{}
+			}
+			interaction.Response.Headers[name] = value
+		}
+	}
+
+	s := t.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The cassette is written after every interaction, as the provider
+	// process has no hook to flush it on exit.
+	s.cassette.Interactions = append(s.cassette.Interactions, interaction)
+	data, err := json.MarshalIndent(s.cassette, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return nil, fmt.Errorf("creating cassette directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, append(data, '\n'), 0o644); err != nil {
+		return nil, fmt.Errorf("writing cassette: %w", err)
+	}
+
+	return resp, nil
+}
+
+// sanitize scrubs secrets, the project id and sensitive JSON fields from s.
+func (t *vcrTransport) sanitize(s string) string {
+	for _, secret := range t.secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, redacted)
+		}
+	}
+	s = projectIDFieldRe.ReplaceAllString(s, redactedProjectID)
+	s = sensitiveBodyFieldRe.ReplaceAllStringFunc(s, func(field string) string {
+		key, _, _ := strings.Cut(field, ":")
+		return key + `:"` + redacted + `"`
+	})
+	return bearerRe.ReplaceAllString(s, "Bearer "+redacted)
+}
@@ -0,0 +1,66 @@
+package uptrace_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	uptrace "github.com/persona-ae/terraform-provider-uptrace/internal/services"
+)
+
+func TestVCRTransport(t *testing.T) {
+	const apiKey = "secret-api-key"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"monitor":{"id":3,"projectId":42,"name":"spans","token":"`+apiKey+`"}}`)
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+
+	get := func(mode, cassette, projectID string) string {
+		t.Helper()
+		transport, err := uptrace.NewVCRTransport(mode, cassette, http.DefaultTransport, projectID, apiKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := http.NewRequest("GET", srv.URL+"/internal/v1/projects/"+projectID+"/monitors/3", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	get(uptrace.VCRModeRecord, filepath.Join(dir, "record.json"), "42")
+	recorded, err := os.ReadFile(filepath.Join(dir, "record.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leak := range []string{apiKey, "42"} {
+		if strings.Contains(string(recorded), leak) {
+			t.Errorf("cassette contains %q:\n%s", leak, recorded)
+		}
+	}
+
+	// Cassettes are kept open by path for the process, replay a copy.
+	if err := os.WriteFile(filepath.Join(dir, "replay.json"), recorded, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+	if got := get(uptrace.VCRModeReplay, filepath.Join(dir, "replay.json"), "7"); !strings.Contains(got, `"projectId":7`) {
+		t.Errorf("replayed %s, want the project id 7", got)
+	}
+}
@@ -0,0 +1,139 @@
+// Package telemetry sets up OpenTelemetry tracing of the provider itself, so
+// that slow Terraform runs can be traced in Uptrace.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// InstrumentationName identifies the spans of the provider.
+	InstrumentationName = "github.com/persona-ae/terraform-provider-uptrace"
+
+	// EnvTraceFile names a file the spans are written to as JSON, eg. to
+	// inspect them without a collector.
+	EnvTraceFile = "UPTRACE_PROVIDER_TRACE_FILE"
+)
+
+var (
+	mu       sync.Mutex
+	provider *sdktrace.TracerProvider
+	closers  []func() error
+)
+
+// Init installs a global tracer provider when tracing is enabled through the
+// environment: spans are exported with OTLP over HTTP when
+// OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set,
+// and written to the file in UPTRACE_PROVIDER_TRACE_FILE. Otherwise tracing
+// stays disabled and spans cost nothing. The remaining OTEL_* variables, such
+// as OTEL_EXPORTER_OTLP_HEADERS, are honoured by the exporter.
+func Init(ctx context.Context, version string) error {
+	var exporters []sdktrace.SpanExporter
+
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return fmt.Errorf("creating OTLP exporter: %w", err)
+		}
+		exporters = append(exporters, exporter)
+	}
+
+	if filename := os.Getenv(EnvTraceFile); filename != "" {
+		f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("opening trace file: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return fmt.Errorf("creating file exporter: %w", err)
+		}
+		exporters = append(exporters, exporter)
+
+		mu.Lock()
+		closers = append(closers, f.Close)
+		mu.Unlock()
+	}
+
+	if len(exporters) == 0 {
+		return nil
+	}
+
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName("terraform-provider-uptrace"),
+			semconv.ServiceVersion(version),
+		)),
+	}
+	for _, exporter := range exporters {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+	install(sdktrace.NewTracerProvider(opts...))
+	return nil
+}
+
+// InitWithExporter installs a global tracer provider exporting synchronously
+// to exporter, eg. a tracetest.InMemoryExporter in tests.
+func InitWithExporter(exporter sdktrace.SpanExporter) {
+	install(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+}
+
+func install(tp *sdktrace.TracerProvider) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	provider = tp
+	otel.SetTracerProvider(tp)
+}
+
+// Shutdown flushes pending spans and stops the exporters. It must be called
+// before the provider process exits.
+func Shutdown(ctx context.Context) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var errs []error
+	if provider != nil {
+		errs = append(errs, provider.Shutdown(ctx))
+		provider = nil
+	}
+	for _, closer := range closers {
+		errs = append(errs, closer())
+	}
+	closers = nil
+	return errors.Join(errs...)
+}
+
+// Tracer returns the tracer of the provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// StartSpan starts a span named name with the given attributes.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err, if any, on span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,35 @@
+package uql
+
+import (
+	"slices"
+	"strings"
+)
+
+// Funcs are the functions Uptrace is known to support in monitor queries.
+// The list is used to catch typos, it is not exhaustive.
+var Funcs = []string{
+	// aggregations
+	"sum", "avg", "min", "max", "count", "last", "uniq",
+	"p50", "p75", "p90", "p95", "p99",
+	"histogram_quantile",
+
+	// top and bottom series
+	"top3", "top5", "top10", "bottom3", "bottom5", "bottom10",
+
+	// rates, in the current and the legacy spelling
+	"per_min", "per_sec", "perMin", "perSec",
+	"delta", "irate", "increase",
+
+	// math
+	"abs", "ceil", "floor", "trunc", "exp", "log", "log2", "log10",
+
+	// attributes
+	"lower", "upper",
+}
+
+// IsFunc reports whether name is a function in Funcs, ignoring case.
+func IsFunc(name string) bool {
+	return slices.ContainsFunc(Funcs, func(fn string) bool {
+		return strings.EqualFold(fn, name)
+	})
+}
@@ -0,0 +1,167 @@
+package uql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenAlias
+	tokenNumber
+	tokenString
+	tokenOperator
+	tokenPunct
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of query"
+	case tokenIdent:
+		return "identifier"
+	case tokenAlias:
+		return "metric alias"
+	case tokenNumber:
+		return "number"
+	case tokenString:
+		return "string"
+	default:
+		return "symbol"
+	}
+}
+
+type token struct {
+	kind tokenKind
+	// text is the token as written, without the $ of aliases and the quotes
+	// of strings.
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return t.kind.String()
+	case tokenAlias:
+		return fmt.Sprintf("%q", "$"+t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// is reports whether the token is the keyword or symbol s. Keywords are case
+// insensitive.
+func (t token) is(s string) bool {
+	switch t.kind {
+	case tokenIdent:
+		return strings.EqualFold(t.text, s)
+	case tokenOperator, tokenPunct:
+		return t.text == s
+	}
+	return false
+}
+
+// lex splits a query into tokens, ending with a tokenEOF.
+func lex(query string) ([]token, error) {
+	var tokens []token
+	for pos := 0; pos < len(query); {
+		r, size := utf8.DecodeRuneInString(query[pos:])
+		start := pos
+
+		switch {
+		case unicode.IsSpace(r):
+			pos += size
+			continue
+
+		case r == '$':
+			pos += size
+			end := scanIdent(query, pos)
+			if end == pos {
+				return nil, &Error{Pos: start, Msg: "expected a metric alias after \"$\""}
+			}
+			tokens = append(tokens, token{kind: tokenAlias, text: query[pos:end], pos: start})
+			pos = end
+
+		case isIdentStart(r):
+			pos = scanIdent(query, pos)
+			tokens = append(tokens, token{kind: tokenIdent, text: query[start:pos], pos: start})
+
+		case r >= '0' && r <= '9' || r == '.' && pos+1 < len(query) && isDigit(query[pos+1]):
+			pos = scanNumber(query, pos)
+			tokens = append(tokens, token{kind: tokenNumber, text: query[start:pos], pos: start})
+
+		case r == '"' || r == '\'':
+			end := strings.IndexRune(query[pos+size:], r)
+			if end < 0 {
+				return nil, &Error{Pos: start, Msg: "unterminated string"}
+			}
+			pos += size + end + size
+			tokens = append(tokens, token{kind: tokenString, text: query[start+size : pos-size], pos: start})
+
+		case strings.ContainsRune("!<>=", r):
+			pos += size
+			if pos < len(query) && (query[pos] == '=' || r == '!' && query[pos] == '~') {
+				pos++
+			}
+			op := query[start:pos]
+			if op == "!" {
+				return nil, &Error{Pos: start, Msg: "unexpected \"!\", did you mean \"!=\" or \"!~\"?"}
+			}
+			if op == "==" {
+				op = "="
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: start})
+
+		case strings.ContainsRune("+-*/%~", r):
+			pos += size
+			tokens = append(tokens, token{kind: tokenOperator, text: query[start:pos], pos: start})
+
+		case strings.ContainsRune("(),|{}", r):
+			pos += size
+			tokens = append(tokens, token{kind: tokenPunct, text: query[start:pos], pos: start})
+
+		default:
+			return nil, &Error{Pos: start, Msg: fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(query)}), nil
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// scanIdent returns the end of the identifier starting at pos. Identifiers
+// may contain dots, like attribute names such as host.name.
+func scanIdent(s string, pos int) int {
+	for pos < len(s) {
+		r, size := utf8.DecodeRuneInString(s[pos:])
+		if !isIdentStart(r) && !unicode.IsDigit(r) && r != '.' {
+			break
+		}
+		pos += size
+	}
+	return pos
+}
+
+// scanNumber returns the end of the number starting at pos, allowing a
+// fraction and a unit suffix such as 5m or 1.5kb.
+func scanNumber(s string, pos int) int {
+	for pos < len(s) && (isDigit(s[pos]) || s[pos] == '.') {
+		pos++
+	}
+	for pos < len(s) && (s[pos] >= 'a' && s[pos] <= 'z' || s[pos] >= 'A' && s[pos] <= 'Z') {
+		pos++
+	}
+	return pos
+}
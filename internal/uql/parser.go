@@ -0,0 +1,399 @@
+package uql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// comparisonOps are the operators of conditions, besides the keywords
+// "like", "in", "contains" and "exists" and their negations. "~" and "!~"
+// match regular expressions.
+var comparisonOps = map[string]bool{
+	"=": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true,
+	"~": true, "!~": true,
+}
+
+// Parse parses a query. Syntax errors are returned as *Error.
+func Parse(query string) (*Query, error) {
+	tokens, err := lex(query)
+	if err == nil {
+		p := &parser{query: query, tokens: tokens}
+		var q *Query
+		q, err = p.parseQuery()
+		if err == nil {
+			return q, nil
+		}
+	}
+
+	// Fill in the line and column for the error message.
+	if e, ok := err.(*Error); ok {
+		before := query[:e.Pos]
+		e.Line = strings.Count(before, "\n") + 1
+		e.Column = len([]rune(before[strings.LastIndex(before, "\n")+1:])) + 1
+	}
+	return nil, err
+}
+
+type parser struct {
+	query  string
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the keyword or symbol s.
+func (p *parser) accept(s string) bool {
+	if p.peek().is(s) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(s string) (token, error) {
+	t := p.peek()
+	if !t.is(s) {
+		return t, p.errorf(t, "expected %q, got %s", s, t)
+	}
+	return p.next(), nil
+}
+
+func (p *parser) errorf(t token, format string, args ...any) error {
+	return &Error{Pos: t.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parseQuery() (*Query, error) {
+	q := &Query{}
+	for {
+		if err := p.parsePart(q); err != nil {
+			return nil, err
+		}
+		if p.accept("|") {
+			continue
+		}
+		if t := p.peek(); t.kind != tokenEOF {
+			return nil, p.errorf(t, "unexpected %s, expected \"|\" or %s", t, tokenEOF)
+		}
+		return q, nil
+	}
+}
+
+func (p *parser) parsePart(q *Query) error {
+	switch t := p.peek(); {
+	case t.kind == tokenEOF || t.is("|"):
+		return p.errorf(t, "expected columns, \"where\" or \"group by\", got %s", t)
+
+	case t.is("where"):
+		p.next()
+		cond, err := p.parseCondition()
+		if err != nil {
+			return err
+		}
+		q.Where = append(q.Where, cond)
+		return nil
+
+	case t.is("group"):
+		p.next()
+		if _, err := p.expect("by"); err != nil {
+			return err
+		}
+		for {
+			t := p.next()
+			if t.kind != tokenIdent || isKeyword(t.text) {
+				return p.errorf(t, "expected an attribute to group by, got %s", t)
+			}
+			q.GroupBy = append(q.GroupBy, t.text)
+			if !p.accept(",") {
+				return nil
+			}
+		}
+	}
+
+	for {
+		column, err := p.parseColumn()
+		if err != nil {
+			return err
+		}
+		q.Columns = append(q.Columns, column)
+		if !p.accept(",") {
+			return nil
+		}
+	}
+}
+
+func (p *parser) parseColumn() (Column, error) {
+	start := p.peek().pos
+	expr, err := p.parseExpr()
+	if err != nil {
+		return Column{}, err
+	}
+	column := Column{
+		Expr: expr,
+		Text: strings.TrimSpace(p.query[start:p.peek().pos]),
+	}
+
+	if p.accept("as") {
+		t := p.next()
+		if t.kind != tokenIdent || isKeyword(t.text) || strings.Contains(t.text, ".") {
+			return Column{}, p.errorf(t, "expected a column name after \"as\", got %s", t)
+		}
+		column.Alias = t.text
+	}
+	return column, nil
+}
+
+// parseCondition parses a boolean expression of comparisons combined with
+// "and", "or" and "not".
+func (p *parser) parseCondition() (Expr, error) {
+	x, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("or") {
+		y, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		x = &Binary{Op: "or", X: x, Y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	x, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("and") {
+		y, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		x = &Binary{Op: "and", X: x, Y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseNot() (Expr, error) {
+	if t := p.peek(); t.is("not") {
+		p.next()
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &Unary{Op: "not", X: x, At: t.pos}, nil
+	}
+	if p.accept("(") {
+		cond, err := p.parseCondition()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return cond, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	x, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	op := strings.ToLower(t.text)
+	switch {
+	case t.kind == tokenOperator && comparisonOps[op]:
+		p.next()
+	case t.is("like"), t.is("in"), t.is("contains"), t.is("exists"):
+		p.next()
+	case t.is("not"):
+		p.next()
+		u := p.next()
+		if !u.is("like") && !u.is("in") && !u.is("contains") && !u.is("exists") {
+			return nil, p.errorf(u, "expected \"like\", \"in\", \"contains\" or \"exists\" after \"not\", got %s", u)
+		}
+		op = "not " + strings.ToLower(u.text)
+	default:
+		return nil, p.errorf(t, "expected a comparison operator, got %s", t)
+	}
+
+	// "exists" is postfix, eg. "host.name exists".
+	if strings.HasSuffix(op, "exists") {
+		return &Unary{Op: op, X: x, At: x.Pos()}, nil
+	}
+
+	var y Expr
+	if strings.HasSuffix(op, "in") {
+		y, err = p.parseList()
+	} else {
+		y, err = p.parseExpr()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Binary{Op: op, X: x, Y: y}, nil
+}
+
+func (p *parser) parseList() (Expr, error) {
+	open, err := p.expect("(")
+	if err != nil {
+		return nil, err
+	}
+	list := &List{At: open.pos}
+	for {
+		value, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		list.Values = append(list.Values, value)
+		if !p.accept(",") {
+			break
+		}
+	}
+	if _, err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// parseExpr parses an arithmetic expression.
+func (p *parser) parseExpr() (Expr, error) {
+	x, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t.is("+") || t.is("-"); t = p.peek() {
+		p.next()
+		y, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		x = &Binary{Op: t.text, X: x, Y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseTerm() (Expr, error) {
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t.is("*") || t.is("/") || t.is("%"); t = p.peek() {
+		p.next()
+		y, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		x = &Binary{Op: t.text, X: x, Y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if t := p.peek(); t.is("-") {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Unary{Op: "-", X: x, At: t.pos}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		return &Literal{Value: t.text, At: t.pos}, nil
+
+	case tokenString:
+		return &Literal{Value: strconv.Quote(t.text), At: t.pos}, nil
+
+	case tokenAlias:
+		alias := &Alias{Name: t.text, At: t.pos}
+		if p.accept("{") {
+			if !p.accept("}") {
+				for {
+					cond, err := p.parseCondition()
+					if err != nil {
+						return nil, err
+					}
+					alias.Filters = append(alias.Filters, cond)
+					if !p.accept(",") {
+						break
+					}
+				}
+				if _, err := p.expect("}"); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return alias, nil
+
+	case tokenIdent:
+		if isKeyword(t.text) {
+			break
+		}
+		if !p.accept("(") {
+			return &Ident{Name: t.text, At: t.pos}, nil
+		}
+		call := &Call{Func: t.text, At: t.pos}
+		if !p.accept(")") {
+			for {
+				arg, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				call.Args = append(call.Args, arg)
+				if !p.accept(",") {
+					break
+				}
+			}
+			if _, err := p.expect(")"); err != nil {
+				return nil, err
+			}
+		}
+		return call, nil
+
+	case tokenPunct:
+		if t.text != "(" {
+			break
+		}
+		x, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return x, nil
+	}
+	return nil, p.errorf(t, "expected an expression, got %s", t)
+}
+
+// keywords cannot be used as attribute or column names.
+var keywords = map[string]bool{
+	"as": true, "where": true, "group": true, "by": true,
+	"and": true, "or": true, "not": true, "like": true, "in": true,
+	"contains": true, "exists": true,
+}
+
+func isKeyword(s string) bool {
+	return keywords[strings.ToLower(s)]
+}
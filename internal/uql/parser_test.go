@@ -0,0 +1,121 @@
+package uql_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/persona-ae/terraform-provider-uptrace/internal/uql"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query   string
+		names   []string
+		aliases []string
+		funcs   []string
+		groupBy []string
+	}{
+		{
+			query:   "perMin(sum($spans)) as spans",
+			names:   []string{"spans"},
+			aliases: []string{"spans"},
+			funcs:   []string{"perMin", "sum"},
+		},
+		{
+			query:   `sum($errors{status = "error"}) / sum($spans) as error_rate, max($cpu) | where service.name in ("api", "web") and not host.name like "test%" | group by host.name, service.name`,
+			names:   []string{"error_rate", "max($cpu)"},
+			aliases: []string{"errors", "spans", "cpu"},
+			funcs:   []string{"sum", "sum", "max"},
+			groupBy: []string{"host.name", "service.name"},
+		},
+		{
+			query:   "avg($mem) * 100 as mem_percent | where $mem > 0.5 or (host.name != 'a' and region = \"eu\")",
+			names:   []string{"mem_percent"},
+			aliases: []string{"mem", "mem"},
+			funcs:   []string{"avg"},
+		},
+		{
+			query:   `top3(sum($spans{http.route ~ "^/api/", service.name !~ "test"})) as spans | where host.name exists and not region exists and tags contains "eu" and tags not contains "test"`,
+			names:   []string{"spans"},
+			aliases: []string{"spans"},
+			funcs:   []string{"top3", "sum"},
+		},
+		{
+			query:   "$spans\n| GROUP BY service.name",
+			names:   []string{"$spans"},
+			aliases: []string{"spans"},
+			groupBy: []string{"service.name"},
+		},
+	}
+	for _, test := range tests {
+		q, err := uql.Parse(test.query)
+		if err != nil {
+			t.Errorf("Parse(%q): %s", test.query, err)
+			continue
+		}
+
+		var aliases, funcs []string
+		for _, alias := range q.Aliases() {
+			aliases = append(aliases, alias.Name)
+		}
+		for _, call := range q.Funcs() {
+			funcs = append(funcs, call.Func)
+		}
+		if !slices.Equal(q.Names(), test.names) || !slices.Equal(aliases, test.aliases) ||
+			!slices.Equal(funcs, test.funcs) || !slices.Equal(q.GroupBy, test.groupBy) {
+			t.Errorf("Parse(%q) = names %q, aliases %q, funcs %q, group by %q, want %q, %q, %q, %q",
+				test.query, q.Names(), aliases, funcs, q.GroupBy, test.names, test.aliases, test.funcs, test.groupBy)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		query string
+		err   string
+	}{
+		{"", `line 1, column 1: expected columns, "where" or "group by", got end of query`},
+		{"sum($spans", `line 1, column 11: expected ")", got end of query`},
+		{"sum($spans) as", `line 1, column 15: expected a column name after "as", got end of query`},
+		{"sum($spans) |\n| where x = 1", `line 2, column 1: expected columns, "where" or "group by", got "|"`},
+		{"sum($) as spans", `line 1, column 5: expected a metric alias after "$"`},
+		{"sum($spans) | where service.name", `line 1, column 33: expected a comparison operator, got end of query`},
+		{"sum($spans) | group service.name", `line 1, column 21: expected "by", got "service.name"`},
+		{`sum($spans) | where a = "b`, `line 1, column 25: unterminated string`},
+		{`sum($spans) | where a ! "b"`, `line 1, column 23: unexpected "!", did you mean "!=" or "!~"?`},
+		{`sum($spans) | where a not "b"`, `line 1, column 27: expected "like", "in", "contains" or "exists" after "not", got "b"`},
+		{`sum($spans) | where a exists "b"`, `line 1, column 30: unexpected "b", expected "|" or end of query`},
+	}
+	for _, test := range tests {
+		_, err := uql.Parse(test.query)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("Parse(%q) = %v, want error %q", test.query, err, test.err)
+		}
+	}
+}
+
+func TestParseCondition(t *testing.T) {
+	tests := []struct {
+		query string
+		where string
+	}{
+		{`$spans | where a ~ "^x"`, `(a ~ "^x")`},
+		{`$spans | where a !~ "^x"`, `(a !~ "^x")`},
+		{`$spans | where a contains "x"`, `(a contains "x")`},
+		{`$spans | where a NOT CONTAINS "x"`, `(a not contains "x")`},
+		{`$spans | where a exists`, `a exists`},
+		{`$spans | where a not exists or b exists`, `(a not exists or b exists)`},
+		{`$spans | where not a exists`, `not a exists`},
+	}
+	for _, test := range tests {
+		q, err := uql.Parse(test.query)
+		if err != nil {
+			t.Errorf("Parse(%q): %s", test.query, err)
+			continue
+		}
+		if len(q.Where) != 1 || q.Where[0].String() != test.where {
+			t.Errorf("Parse(%q) = where %v, want %s", test.query, q.Where, test.where)
+		}
+	}
+}
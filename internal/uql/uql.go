@@ -0,0 +1,218 @@
+// Package uql parses the subset of the Uptrace query language used by metric
+// monitors, eg.
+//
+//	perMin(sum($spans)) as spans | where service.name = "api" | group by host.name
+//
+// A query is a list of parts separated by "|". A part either selects columns,
+// which are expressions over metric aliases optionally named with "as",
+// filters with "where" or groups with "group by". The parser only checks
+// the structure of a query, which functions exist is left to the caller, see
+// Query.Funcs.
+package uql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Query is a parsed query.
+type Query struct {
+	// Columns are the selected columns, in order.
+	Columns []Column
+	// Where are the conditions of the "where" parts, in order.
+	Where []Expr
+	// GroupBy are the attributes grouped by, in order.
+	GroupBy []string
+}
+
+// Column is a selected expression.
+type Column struct {
+	Expr Expr
+	// Text is the expression as written in the query.
+	Text string
+	// Alias is the name given with "as", empty when there is none.
+	Alias string
+}
+
+// Name returns the name of the column in the query result: its alias or
+// else the expression as written.
+func (c Column) Name() string {
+	if c.Alias != "" {
+		return c.Alias
+	}
+	return c.Text
+}
+
+// Names returns the names of the columns.
+func (q *Query) Names() []string {
+	names := make([]string, len(q.Columns))
+	for i, column := range q.Columns {
+		names[i] = column.Name()
+	}
+	return names
+}
+
+// Aliases returns every reference to a metric alias in the query, in
+// order.
+func (q *Query) Aliases() []*Alias {
+	var aliases []*Alias
+	q.walk(func(e Expr) {
+		if alias, ok := e.(*Alias); ok {
+			aliases = append(aliases, alias)
+		}
+	})
+	return aliases
+}
+
+// Funcs returns the function calls of the query, including nested ones.
+func (q *Query) Funcs() []*Call {
+	var calls []*Call
+	q.walk(func(e Expr) {
+		if call, ok := e.(*Call); ok {
+			calls = append(calls, call)
+		}
+	})
+	return calls
+}
+
+func (q *Query) walk(fn func(Expr)) {
+	for _, column := range q.Columns {
+		walk(column.Expr, fn)
+	}
+	for _, cond := range q.Where {
+		walk(cond, fn)
+	}
+}
+
+func walk(e Expr, fn func(Expr)) {
+	fn(e)
+	switch e := e.(type) {
+	case *Call:
+		for _, arg := range e.Args {
+			walk(arg, fn)
+		}
+	case *Alias:
+		for _, cond := range e.Filters {
+			walk(cond, fn)
+		}
+	case *Unary:
+		walk(e.X, fn)
+	case *Binary:
+		walk(e.X, fn)
+		walk(e.Y, fn)
+	case *List:
+		for _, value := range e.Values {
+			walk(value, fn)
+		}
+	}
+}
+
+// Expr is an expression: a value, a column or a condition.
+type Expr interface {
+	// Pos returns the byte offset of the expression in the query.
+	Pos() int
+	// String returns the expression in its canonical form.
+	String() string
+}
+
+// Alias references a metric by its alias, eg. $spans, optionally filtered,
+// eg. $spans{status = "error"}.
+type Alias struct {
+	Name    string
+	Filters []Expr
+	At      int
+}
+
+// Call is a function call, eg. sum($spans).
+type Call struct {
+	Func string
+	Args []Expr
+	At   int
+}
+
+// Ident is an attribute name, eg. host.name.
+type Ident struct {
+	Name string
+	At   int
+}
+
+// Literal is a number or string literal. Strings keep their quotes.
+type Literal struct {
+	Value string
+	At    int
+}
+
+// Unary is a unary operation, eg. -x, not x or the postfix x exists.
+type Unary struct {
+	Op string
+	X  Expr
+	At int
+}
+
+// Binary is an arithmetic, comparison or logical operation, eg. x / y or
+// host.name = "a".
+type Binary struct {
+	Op   string
+	X, Y Expr
+}
+
+// List is a parenthesized list of values, the right hand side of "in".
+type List struct {
+	Values []Expr
+	At     int
+}
+
+func (e *Alias) Pos() int   { return e.At }
+func (e *Call) Pos() int    { return e.At }
+func (e *Ident) Pos() int   { return e.At }
+func (e *Literal) Pos() int { return e.At }
+func (e *Unary) Pos() int   { return e.At }
+func (e *Binary) Pos() int  { return e.X.Pos() }
+func (e *List) Pos() int    { return e.At }
+
+func (e *Alias) String() string {
+	if len(e.Filters) == 0 {
+		return "$" + e.Name
+	}
+	return "$" + e.Name + "{" + join(e.Filters, ", ") + "}"
+}
+
+func (e *Call) String() string    { return e.Func + "(" + join(e.Args, ", ") + ")" }
+func (e *Ident) String() string   { return e.Name }
+func (e *Literal) String() string { return e.Value }
+func (e *List) String() string    { return "(" + join(e.Values, ", ") + ")" }
+
+func (e *Unary) String() string {
+	switch e.Op {
+	case "-":
+		return "-" + e.X.String()
+	case "exists", "not exists":
+		return e.X.String() + " " + e.Op
+	}
+	return e.Op + " " + e.X.String()
+}
+
+func (e *Binary) String() string {
+	return "(" + e.X.String() + " " + e.Op + " " + e.Y.String() + ")"
+}
+
+func join(exprs []Expr, sep string) string {
+	strs := make([]string, len(exprs))
+	for i, e := range exprs {
+		strs[i] = e.String()
+	}
+	return strings.Join(strs, sep)
+}
+
+// Error is a syntax error in a query.
+type Error struct {
+	// Pos is the byte offset of the error in the query, Line and Column its
+	// position counted from 1.
+	Pos          int
+	Line, Column int
+	Msg          string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
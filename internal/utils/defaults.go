@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/persona-ae/terraform-provider-uptrace/internal/models"
+	"github.com/persona-ae/terraform-provider-uptrace/internal/uql"
+)
+
+// ApplyMonitorDefaults fills the attributes of plan that are not set in
+// config with the provider's monitor defaults, so that the plan shows the
+// effective values. Values set on the resource always win.
+func ApplyMonitorDefaults(defaults models.TFMonitorDefaults, config models.TFMonitorData, plan *models.TFMonitorData) {
+	if useDefault(config.NotifyEveryoneByEmail, defaults.NotifyEveryoneByEmail) {
+		plan.NotifyEveryoneByEmail = defaults.NotifyEveryoneByEmail
+	}
+	if useDefault(config.RepeatInterval, defaults.RepeatInterval) {
+		plan.RepeatInterval = defaults.RepeatInterval
+	}
+	if useDefault(config.NullsMode, defaults.NullsMode) {
+		plan.NullsMode = defaults.NullsMode
+	}
+	if useDefault(config.Tolerance, defaults.Tolerance) {
+		plan.Tolerance = defaults.Tolerance
+	}
+	if useDefault(config.GroupingInterval, defaults.GroupingInterval) {
+		plan.GroupingInterval = defaults.GroupingInterval
+	}
+	if useDefault(config.CheckNumPoint, defaults.CheckNumPoint) {
+		plan.CheckNumPoint = defaults.CheckNumPoint
+	}
+	if useDefault(config.TimeOffset, defaults.TimeOffset) {
+		plan.TimeOffset = defaults.TimeOffset
+	}
+	if useDefault(config.TrainingPeriod, defaults.TrainingPeriod) {
+		plan.TrainingPeriod = defaults.TrainingPeriod
+	}
+	if useDefault(config.MinDevFraction, defaults.MinDevFraction) {
+		plan.MinDevFraction = defaults.MinDevFraction
+	}
+	if useDefault(config.TeamIDs, defaults.TeamIDs) {
+		plan.TeamIDs = defaults.TeamIDs
+	}
+	if useDefault(config.ChannelIDs, defaults.ChannelIDs) {
+		plan.ChannelIDs = defaults.ChannelIDs
+	}
+}
+
+func useDefault(config, def attr.Value) bool {
+	return config.IsNull() && !def.IsNull()
+}
+
+// DeriveMonitorColumn plans the column of a metric monitor as the only column
+// of its query when config does not set one. Queries with several columns
+// require the column to be configured, see ValidateMonitorConfig.
+func DeriveMonitorColumn(config models.TFMonitorData, plan *models.TFMonitorData) {
+	if !config.Column.IsNull() || !known(config.Type) || config.Type.ValueString() != "metric" || !known(config.Query) {
+		return
+	}
+
+	query, err := uql.Parse(config.Query.ValueString())
+	if err != nil || len(query.Columns) != 1 {
+		return
+	}
+	plan.Column = types.StringValue(query.Columns[0].Name())
+}
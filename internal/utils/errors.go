@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	uptrace "github.com/persona-ae/terraform-provider-uptrace/internal/services"
+)
+
+// monitorFieldAttributes maps the JSON fields of an Uptrace monitor to the
+// attributes of the uptrace_monitor resource.
+var monitorFieldAttributes = map[string]string{
+	"name":                            "name",
+	"type":                            "type",
+	"status":                          "status",
+	"projectId":                       "project_id",
+	"notifyEveryoneByEmail":           "notify_everyone_by_email",
+	"repeatInterval":                  "repeat_interval",
+	"repeatInterval.strategy":         "repeat_interval",
+	"teamIds":                         "team_ids",
+	"channelIds":                      "channel_ids",
+	"params.metrics":                  "metrics",
+	"params.query":                    "query",
+	"params.column":                   "column",
+	"params.columnUnit":               "column_unit",
+	"params.boundsSource":             "bounds_source",
+	"params.groupingInterval":         "grouping_interval",
+	"params.checkNumPoint":            "check_num_point",
+	"params.nullsMode":                "nulls_mode",
+	"params.timeOffset":               "time_offset",
+	"params.minAllowedValue":          "min_allowed_value",
+	"params.maxAllowedValue":          "max_allowed_value",
+	"params.flapping.minAllowedValue": "min_allowed_flapping_value",
+	"params.flapping.maxAllowedValue": "max_allowed_flapping_value",
+	"params.tolerance":                "tolerance",
+	"params.trainingPeriod":           "training_period",
+	"params.minDevFraction":           "min_dev_fraction",
+	"params.minDevValue":              "min_dev_value",
+}
+
+var metricFieldRe = regexp.MustCompile(`^params\.metrics\[(\d+)\](?:\.(name|alias))?$`)
+
+// MonitorFieldPath returns the attribute path of uptrace_monitor matching the
+// JSON field reported in an Uptrace validation error.
+func MonitorFieldPath(field string) (path.Path, bool) {
+	if attribute, ok := monitorFieldAttributes[field]; ok {
+		return path.Root(attribute), true
+	}
+
+	if m := metricFieldRe.FindStringSubmatch(field); m != nil {
+		idx, err := strconv.Atoi(m[1])
+		if err != nil {
+			return path.Empty(), false
+		}
+		p := path.Root("metrics").AtListIndex(idx)
+		if m[2] != "" {
+			p = p.AtName(m[2])
+		}
+		return p, true
+	}
+
+	return path.Empty(), false
+}
+
+// MonitorAPIErrorDiagnostics converts an error returned by the Uptrace client
+// into diagnostics, attaching validation errors to the matching attributes.
+func MonitorAPIErrorDiagnostics(summary string, err error) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if errors.Is(err, uptrace.ErrReadOnly) {
+		diags.AddError(
+			"Provider is read-only",
+			"The Uptrace provider is configured with read_only, so monitors cannot be created, updated or deleted. "+
+				"Unset read_only, or the UPTRACE_READ_ONLY environment variable, to apply changes.",
+		)
+		return diags
+	}
+
+	apiErr, ok := uptrace.AsAPIError(err)
+	if !ok || len(apiErr.Fields) == 0 {
+		diags.AddError(summary, fmt.Sprintf("%s: %s", summary, err))
+		return diags
+	}
+
+	for _, f := range apiErr.Fields {
+		if p, ok := MonitorFieldPath(f.Field); ok {
+			diags.AddAttributeError(p, summary, f.Message)
+			continue
+		}
+		diags.AddError(summary, fmt.Sprintf("%s: %s", f.Field, f.Message))
+	}
+	if apiErr.Message != "" {
+		diags.AddError(summary, fmt.Sprintf("%s: %s", summary, apiErr.Message))
+	}
+
+	return diags
+}
@@ -0,0 +1,74 @@
+package utils_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	uptrace "github.com/persona-ae/terraform-provider-uptrace/internal/services"
+	"github.com/persona-ae/terraform-provider-uptrace/internal/utils"
+)
+
+func TestMonitorFieldPath(t *testing.T) {
+	tests := []struct {
+		field string
+		want  path.Path
+	}{
+		{"name", path.Root("name")},
+		{"repeatInterval.strategy", path.Root("repeat_interval")},
+		{"params.flapping.maxAllowedValue", path.Root("max_allowed_flapping_value")},
+		{"params.metrics", path.Root("metrics")},
+		{"params.metrics[1]", path.Root("metrics").AtListIndex(1)},
+		{"params.metrics[1].alias", path.Root("metrics").AtListIndex(1).AtName("alias")},
+	}
+	for _, test := range tests {
+		got, ok := utils.MonitorFieldPath(test.field)
+		if !ok || !got.Equal(test.want) {
+			t.Errorf("MonitorFieldPath(%q) = %s, %t, want %s", test.field, got, ok, test.want)
+		}
+	}
+
+	for _, field := range []string{"", "params", "params.unknown", "params.metrics[x]", "params.metrics[0].unit"} {
+		if got, ok := utils.MonitorFieldPath(field); ok {
+			t.Errorf("MonitorFieldPath(%q) = %s, want no path", field, got)
+		}
+	}
+}
+
+func TestMonitorAPIErrorDiagnostics(t *testing.T) {
+	apiErr := &uptrace.APIError{
+		StatusCode: 400,
+		Status:     "400 Bad Request",
+		Message:    "monitor is invalid",
+		Fields: []uptrace.FieldError{
+			{Field: "params.metrics[1].alias", Message: "must be unique"},
+			{Field: "params.unknown", Message: "is not supported"},
+		},
+	}
+	diags := utils.MonitorAPIErrorDiagnostics("Failed to create monitor", fmt.Errorf("creating: %w", apiErr))
+
+	want := diag.Diagnostics{
+		diag.NewAttributeErrorDiagnostic(path.Root("metrics").AtListIndex(1).AtName("alias"), "Failed to create monitor", "must be unique"),
+		diag.NewErrorDiagnostic("Failed to create monitor", "params.unknown: is not supported"),
+		diag.NewErrorDiagnostic("Failed to create monitor", "Failed to create monitor: monitor is invalid"),
+	}
+	if !diags.Equal(want) {
+		t.Errorf("got diagnostics %v, want %v", diags, want)
+	}
+
+	// Errors without fields are reported as a whole.
+	err := errors.New("performing request: connection refused")
+	want = diag.Diagnostics{
+		diag.NewErrorDiagnostic("Failed to create monitor", "Failed to create monitor: performing request: connection refused"),
+	}
+	if diags := utils.MonitorAPIErrorDiagnostics("Failed to create monitor", err); !diags.Equal(want) {
+		t.Errorf("got diagnostics %v, want %v", diags, want)
+	}
+
+	diags = utils.MonitorAPIErrorDiagnostics("Failed to create monitor", uptrace.ErrReadOnly)
+	if len(diags) != 1 || diags[0].Summary() != "Provider is read-only" {
+		t.Errorf("got diagnostics %v, want Provider is read-only", diags)
+	}
+}
@@ -0,0 +1,238 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/persona-ae/terraform-provider-uptrace/internal/models"
+	"github.com/persona-ae/terraform-provider-uptrace/internal/uql"
+)
+
+// ValidateMonitorConfig checks the rules Uptrace applies across the fields of
+// a monitor, so that they are reported by terraform validate rather than by
+// the API on apply. Values that are unknown are skipped, they are checked
+// again once known.
+func ValidateMonitorConfig(config models.TFMonitorData, diags *diag.Diagnostics) {
+	// Error monitors query spans rather than metrics, and have no bounds.
+	if config.Type.IsUnknown() || config.Type.ValueString() != "metric" {
+		return
+	}
+
+	validateMonitorQuery(config, diags)
+	validateMonitorBounds(config, diags)
+}
+
+// validateMonitorQuery checks that the query parses, names its columns apart
+// and only references the aliases of the monitor's metrics. Calls to functions missing from
+// uql.Funcs are only warned about, as Uptrace may support more of them.
+func validateMonitorQuery(config models.TFMonitorData, diags *diag.Diagnostics) {
+	if !known(config.Query) {
+		return
+	}
+
+	query, err := uql.Parse(config.Query.ValueString())
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("query"),
+			"Invalid monitor query",
+			fmt.Sprintf("The query could not be parsed, %s.", err),
+		)
+		return
+	}
+
+	for _, call := range query.Funcs() {
+		if uql.IsFunc(call.Func) {
+			continue
+		}
+		detail := fmt.Sprintf("The query calls the unknown function %q, Uptrace will reject the monitor if it does not support it.", call.Func)
+		if suggestion, ok := closest(call.Func, uql.Funcs); ok {
+			detail += fmt.Sprintf(" Did you mean %q?", suggestion)
+		}
+		diags.AddAttributeWarning(path.Root("query"), "Unknown function in monitor query", detail)
+	}
+
+	// Column names must be unique for "column" to tell them apart.
+	seen := make(map[string]bool)
+	for _, name := range query.Names() {
+		if seen[name] {
+			diags.AddAttributeError(
+				path.Root("query"),
+				"Duplicate monitor query column",
+				fmt.Sprintf("The query has several columns named %q, give them different names with \"as\".", name),
+			)
+		}
+		seen[name] = true
+	}
+
+	validateMonitorColumn(config.Column, query, diags)
+
+	aliases, ok := metricAliases(config.Metrics)
+	if !ok {
+		return
+	}
+	reported := make(map[string]bool)
+	for _, alias := range query.Aliases() {
+		if slices.Contains(aliases, alias.Name) || reported[alias.Name] {
+			continue
+		}
+		reported[alias.Name] = true
+
+		detail := fmt.Sprintf("The query references $%s, which is not the alias of any of the monitor's metrics.", alias.Name)
+		if suggestion, ok := closest(alias.Name, aliases); ok {
+			detail += fmt.Sprintf(" Did you mean $%s?", suggestion)
+		} else {
+			detail += fmt.Sprintf(" Add a metric with the alias %q to \"metrics\".", alias.Name)
+		}
+		diags.AddAttributeError(path.Root("query"), "Undefined metric alias in monitor query", detail)
+	}
+}
+
+// validateMonitorColumn checks that the column is one of the query's columns,
+// and that it is set when the query has several.
+func validateMonitorColumn(column types.String, query *uql.Query, diags *diag.Diagnostics) {
+	if column.IsUnknown() {
+		return
+	}
+
+	names := query.Names()
+	if column.IsNull() {
+		if len(names) > 1 {
+			diags.AddAttributeError(
+				path.Root("column"),
+				"Missing monitor column",
+				fmt.Sprintf("The query has several columns, set \"column\" to the one to monitor: %s.", strings.Join(quoted(names), ", ")),
+			)
+		}
+		return
+	}
+
+	if len(names) == 0 || slices.Contains(names, column.ValueString()) {
+		return
+	}
+	detail := fmt.Sprintf("%q is not a column of the query. The query has the columns: %s.", column.ValueString(), strings.Join(quoted(names), ", "))
+	if suggestion, ok := closest(column.ValueString(), names); ok {
+		detail += fmt.Sprintf(" Did you mean %q?", suggestion)
+	}
+	diags.AddAttributeError(path.Root("column"), "Invalid monitor column", detail)
+}
+
+func quoted(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value)
+	}
+	return quoted
+}
+
+// metricAliases returns the aliases declared in metrics, or false when they
+// are not all known yet.
+func metricAliases(metrics types.List) ([]string, bool) {
+	if !known(metrics) {
+		return nil, false
+	}
+	var aliases []string
+	for _, element := range metrics.Elements() {
+		object, ok := element.(types.Object)
+		if !ok || !known(object) {
+			return nil, false
+		}
+		alias, ok := object.Attributes()["alias"].(types.String)
+		if !ok || alias.IsUnknown() {
+			return nil, false
+		}
+		aliases = append(aliases, alias.ValueString())
+	}
+	return aliases, true
+}
+
+// validateMonitorBounds checks the trigger and flapping bounds of monitors
+// with manual bounds, the default.
+func validateMonitorBounds(config models.TFMonitorData, diags *diag.Diagnostics) {
+	if config.BoundsSource.IsUnknown() || config.BoundsSource.ValueString() == "auto" {
+		return
+	}
+
+	// Tolerance and training period tune the automatic bounds.
+	checkAutoOnly(config.Tolerance, "tolerance", diags)
+	checkAutoOnly(config.TrainingPeriod, "training_period", diags)
+
+	if config.MinAllowedValue.IsNull() && config.MaxAllowedValue.IsNull() {
+		diags.AddAttributeError(
+			path.Root("max_allowed_value"),
+			"Missing monitor bounds",
+			"At least one of \"min_allowed_value\" or \"max_allowed_value\" is required for metric monitors "+
+				"with manual bounds. Set one of them, or set \"bounds_source\" to \"auto\".",
+		)
+	}
+
+	// The flapping bounds close an alert, so they must lie inside the bounds
+	// that trigger it.
+	checkFlappingBound(config.MinAllowedFlappingValue, "min_allowed_flapping_value", config.MinAllowedValue, "min_allowed_value", 1, diags)
+	checkFlappingBound(config.MaxAllowedFlappingValue, "max_allowed_flapping_value", config.MaxAllowedValue, "max_allowed_value", -1, diags)
+	if known(config.MinAllowedFlappingValue) && known(config.MaxAllowedValue) &&
+		config.MinAllowedFlappingValue.ValueFloat64() > config.MaxAllowedValue.ValueFloat64() {
+		addFlappingError("min_allowed_flapping_value", "max_allowed_value", "at most", config.MaxAllowedValue.ValueFloat64(), diags)
+	}
+	if known(config.MaxAllowedFlappingValue) && known(config.MinAllowedValue) &&
+		config.MaxAllowedFlappingValue.ValueFloat64() < config.MinAllowedValue.ValueFloat64() {
+		addFlappingError("max_allowed_flapping_value", "min_allowed_value", "at least", config.MinAllowedValue.ValueFloat64(), diags)
+	}
+}
+
+// checkAutoOnly reports value when it is set on a monitor with manual bounds.
+// Unknown values may still turn out null, they are checked once known.
+func checkAutoOnly(value attr.Value, attribute string, diags *diag.Diagnostics) {
+	if !known(value) {
+		return
+	}
+	diags.AddAttributeError(
+		path.Root(attribute),
+		"Invalid monitor configuration",
+		fmt.Sprintf("%q is only used with automatic bounds. Set \"bounds_source\" to \"auto\" or remove %q.", attribute, attribute),
+	)
+}
+
+// checkFlappingBound checks that the flapping bound is set together with its
+// trigger bound and lies on its inner side: above it when sign is 1, below it
+// when sign is -1.
+func checkFlappingBound(flapping types.Float64, flappingAttribute string, bound types.Float64, boundAttribute string, sign float64, diags *diag.Diagnostics) {
+	if flapping.IsNull() {
+		return
+	}
+	if bound.IsNull() {
+		diags.AddAttributeError(
+			path.Root(flappingAttribute),
+			"Invalid monitor configuration",
+			fmt.Sprintf("%q requires %q to be set.", flappingAttribute, boundAttribute),
+		)
+		return
+	}
+	if !known(flapping) || !known(bound) {
+		return
+	}
+	if (flapping.ValueFloat64()-bound.ValueFloat64())*sign < 0 {
+		relation := "at least"
+		if sign < 0 {
+			relation = "at most"
+		}
+		addFlappingError(flappingAttribute, boundAttribute, relation, bound.ValueFloat64(), diags)
+	}
+}
+
+func addFlappingError(flappingAttribute, boundAttribute, relation string, bound float64, diags *diag.Diagnostics) {
+	diags.AddAttributeError(
+		path.Root(flappingAttribute),
+		"Invalid monitor flapping bounds",
+		fmt.Sprintf("%q must be %s %q (%g), the flapping bounds must lie inside the bounds triggering the alert.",
+			flappingAttribute, relation, boundAttribute, bound),
+	)
+}
+
+func known(value attr.Value) bool {
+	return !value.IsNull() && !value.IsUnknown()
+}
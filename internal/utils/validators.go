@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ validator.String = oneOfValidator{}
+
+// oneOfValidator checks that a string is one of a fixed set of values,
+// suggesting the closest value for typos.
+type oneOfValidator struct {
+	values []string
+}
+
+// OneOf returns a validator accepting only the given values. Unknown and null
+// values are accepted, they are checked once known.
+func OneOf(values ...string) validator.String {
+	return oneOfValidator{values: values}
+}
+
+func (v oneOfValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("value must be one of: %s", strings.Join(quote(v.values, `"`), ", "))
+}
+
+func (v oneOfValidator) MarkdownDescription(ctx context.Context) string {
+	return fmt.Sprintf("value must be one of: %s", strings.Join(quote(v.values, "`"), ", "))
+}
+
+func (v oneOfValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	if slices.Contains(v.values, value) {
+		return
+	}
+
+	detail := fmt.Sprintf("Attribute %s %s, got: %q.", req.Path, v.Description(ctx), value)
+	if suggestion, ok := closest(value, v.values); ok {
+		detail += fmt.Sprintf(" Did you mean %q?", suggestion)
+	}
+	resp.Diagnostics.AddAttributeError(req.Path, "Invalid Attribute Value", detail)
+}
+
+// EnumDescription lists the allowed values of an attribute for its
+// documentation, eg. "One of `low`, `medium` or `high`.".
+func EnumDescription(values []string) string {
+	quoted := quote(values, "`")
+	if len(quoted) == 1 {
+		return fmt.Sprintf("Must be %s.", quoted[0])
+	}
+	return fmt.Sprintf("One of %s or %s.", strings.Join(quoted[:len(quoted)-1], ", "), quoted[len(quoted)-1])
+}
+
+func quote(values []string, quote string) []string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = quote + value + quote
+	}
+	return quoted
+}
+
+// closest returns the candidate most similar to value, if any is close
+// enough to be a plausible typo.
+func closest(value string, candidates []string) (string, bool) {
+	lower := strings.ToLower(strings.TrimSpace(value))
+
+	best, bestDistance := "", -1
+	for _, candidate := range candidates {
+		if strings.ToLower(candidate) == lower {
+			return candidate, true
+		}
+		if d := levenshtein(lower, strings.ToLower(candidate)); bestDistance < 0 || d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+
+	// Allow roughly one edit per three characters, so that short values
+	// are not matched to anything.
+	if bestDistance < 0 || bestDistance > max(1, len(best)/3) {
+		return "", false
+	}
+	return best, true
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+var _ validator.String = matchesValidator{}
+
+// matchesValidator checks that a string matches a regular expression.
+type matchesValidator struct {
+	re          *regexp.Regexp
+	description string
+}
+
+// Matches returns a validator accepting strings matching re. description
+// explains the expected format, eg. "must be an identifier".
+func Matches(re *regexp.Regexp, description string) validator.String {
+	return matchesValidator{re: re, description: description}
+}
+
+func (v matchesValidator) Description(ctx context.Context) string {
+	return "value " + v.description
+}
+
+func (v matchesValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v matchesValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	if v.re.MatchString(value) {
+		return
+	}
+	resp.Diagnostics.AddAttributeError(
+		req.Path,
+		"Invalid Attribute Value",
+		fmt.Sprintf("Attribute %s %s, got: %q.", req.Path, v.Description(ctx), value),
+	)
+}
+
+var _ validator.List = uniqueAttributeValidator{}
+
+// uniqueAttributeValidator checks that no two objects of a list share the
+// value of an attribute.
+type uniqueAttributeValidator struct {
+	attribute string
+}
+
+// UniqueAttribute returns a validator for lists of objects rejecting
+// duplicate values of attribute. Unknown and null values are not compared.
+func UniqueAttribute(attribute string) validator.List {
+	return uniqueAttributeValidator{attribute: attribute}
+}
+
+func (v uniqueAttributeValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("%q must be unique within the list", v.attribute)
+}
+
+func (v uniqueAttributeValidator) MarkdownDescription(ctx context.Context) string {
+	return fmt.Sprintf("`%s` must be unique within the list", v.attribute)
+}
+
+func (v uniqueAttributeValidator) ValidateList(ctx context.Context, req validator.ListRequest, resp *validator.ListResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	seen := make(map[string]int)
+	for i, element := range req.ConfigValue.Elements() {
+		object, ok := element.(types.Object)
+		if !ok || object.IsNull() || object.IsUnknown() {
+			continue
+		}
+		value, ok := object.Attributes()[v.attribute].(types.String)
+		if !ok || value.IsNull() || value.IsUnknown() {
+			continue
+		}
+
+		if first, ok := seen[value.ValueString()]; ok {
+			resp.Diagnostics.AddAttributeError(
+				req.Path.AtListIndex(i).AtName(v.attribute),
+				"Duplicate Attribute Value",
+				fmt.Sprintf("%q is already used by element %d, %s.", value.ValueString(), first, v.Description(ctx)),
+			)
+			continue
+		}
+		seen[value.ValueString()] = i
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/persona-ae/terraform-provider-uptrace/internal/provider"
+	"github.com/persona-ae/terraform-provider-uptrace/internal/telemetry"
 )
 
 var (
@@ -29,7 +30,19 @@ func main() {
 		Debug:   debug,
 	}
 
-	err := providerserver.Serve(context.Background(), provider.New(version), opts)
+	ctx := context.Background()
+
+	// Tracing is optional, a broken exporter configuration must not stop the
+	// provider from working.
+	if err := telemetry.Init(ctx, version); err != nil {
+		log.Printf("[WARN] OpenTelemetry tracing disabled: %s", err)
+	}
+
+	err := providerserver.Serve(ctx, provider.New(version), opts)
+
+	if shutdownErr := telemetry.Shutdown(ctx); shutdownErr != nil {
+		log.Printf("[WARN] Flushing OpenTelemetry spans: %s", shutdownErr)
+	}
 
 	if err != nil {
 		log.Fatal(err.Error())